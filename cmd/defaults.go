@@ -0,0 +1,134 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/pg-ok/pgok/internal/config"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// Flags that are not check options, skipped in the effective options output
+var skippedOptions = map[string]bool{
+	"config":  true,
+	"explain": true,
+	"help":    true,
+	"output":  true,
+}
+
+// applyConfigDefaults sets flags that were not given on the command line
+// from the config of the database alias (the first argument).
+// Returns the names of the flags set from the config and the database config (nil if not an alias).
+func applyConfigDefaults(cmd *cobra.Command, args []string, cfg *config.DbConfig) (map[string]bool, *config.DatabaseConfig) {
+	fromConfig := make(map[string]bool)
+
+	if len(args) == 0 {
+		return fromConfig, nil
+	}
+
+	dbConfig, ok := cfg.GetDatabase(args[0])
+	if !ok {
+		return fromConfig, nil
+	}
+
+	// Database-wide defaults apply to every command that has the flag
+	defaults := map[string]interface{}{}
+	if dbConfig.Schema != "" {
+		defaults["schema"] = dbConfig.Schema
+	}
+	if len(dbConfig.ExcludeSchemas) > 0 {
+		defaults["exclude-schema"] = dbConfig.ExcludeSchemas
+	}
+	if len(dbConfig.ExcludeTables) > 0 {
+		defaults["exclude-table"] = dbConfig.ExcludeTables
+	}
+	if len(dbConfig.ExpectedOwner) > 0 && cmd.Name() == "schema:owner" {
+		defaults["expected"] = dbConfig.ExpectedOwner
+	}
+
+	// Per-command defaults take precedence and must match an existing flag
+	for name, value := range dbConfig.Checks[cmd.Name()] {
+		if cmd.Flags().Lookup(name) == nil {
+			fmt.Fprintf(os.Stderr, "Warning: unknown option '%s' for %s in config of '%s'\n", name, cmd.Name(), args[0])
+			continue
+		}
+		defaults[name] = value
+	}
+
+	for name, value := range defaults {
+		flag := cmd.Flags().Lookup(name)
+		if flag == nil || flag.Changed {
+			continue
+		}
+
+		if err := setFlagValue(flag, value); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: invalid value for '%s' in config of '%s': %v\n", name, args[0], err)
+			os.Exit(1)
+		}
+		fromConfig[name] = true
+	}
+
+	return fromConfig, &dbConfig
+}
+
+// setFlagValue sets a TOML value (scalar or array) to the flag.
+func setFlagValue(flag *pflag.Flag, value interface{}) error {
+	var values []string
+	switch v := value.(type) {
+	case []interface{}:
+		for _, item := range v {
+			values = append(values, fmt.Sprint(item))
+		}
+	case []string:
+		values = v
+	default:
+		values = []string{fmt.Sprint(v)}
+	}
+
+	if sliceValue, ok := flag.Value.(pflag.SliceValue); ok {
+		return sliceValue.Replace(values)
+	}
+
+	return flag.Value.Set(strings.Join(values, ","))
+}
+
+// printEffectiveOptions prints the option values a check runs with and where they come from.
+func printEffectiveOptions(cmd *cobra.Command, fromConfig map[string]bool, dbConfig *config.DatabaseConfig) {
+	var names []string
+	cmd.Flags().VisitAll(func(flag *pflag.Flag) {
+		if !skippedOptions[flag.Name] {
+			names = append(names, flag.Name)
+		}
+	})
+	sort.Strings(names)
+
+	if len(names) == 0 && dbConfig == nil {
+		return
+	}
+
+	fmt.Println("⚙️ EFFECTIVE OPTIONS")
+	fmt.Println("--------------------")
+	for _, name := range names {
+		flag := cmd.Flags().Lookup(name)
+
+		source := "default"
+		if flag.Changed {
+			source = "flag"
+		} else if fromConfig[name] {
+			source = "config"
+		}
+
+		fmt.Printf("• --%s = %s (%s)\n", name, flag.Value.String(), source)
+	}
+	if dbConfig != nil && dbConfig.StatementTimeout != "" {
+		fmt.Printf("• statement_timeout = %s (config)\n", dbConfig.StatementTimeout)
+	}
+	if dbConfig != nil && len(dbConfig.Tags) > 0 {
+		fmt.Printf("• tags = %s (config)\n", config.FormatTags(dbConfig.Tags))
+	}
+	fmt.Println("")
+}
@@ -1,9 +1,14 @@
 package cmd
 
 import (
+	"fmt"
 	"os"
 
+	"github.com/pg-ok/pgok/internal/cli/app_config_show"
 	"github.com/pg-ok/pgok/internal/cli/app_db_list"
+	"github.com/pg-ok/pgok/internal/cli/column_int_overflow"
+	"github.com/pg-ok/pgok/internal/cli/constraint_fk_type_mismatch"
+	"github.com/pg-ok/pgok/internal/cli/constraint_not_valid"
 	"github.com/pg-ok/pgok/internal/cli/index_cache_hit"
 	"github.com/pg-ok/pgok/internal/cli/index_duplicate"
 	"github.com/pg-ok/pgok/internal/cli/index_invalid"
@@ -11,9 +16,24 @@ import (
 	"github.com/pg-ok/pgok/internal/cli/index_missing_fk"
 	"github.com/pg-ok/pgok/internal/cli/index_size"
 	"github.com/pg-ok/pgok/internal/cli/index_unused"
+	"github.com/pg-ok/pgok/internal/cli/partition_default_missing"
+	"github.com/pg-ok/pgok/internal/cli/partition_gaps"
+	"github.com/pg-ok/pgok/internal/cli/partition_index_missing"
+	"github.com/pg-ok/pgok/internal/cli/partition_skew"
+	"github.com/pg-ok/pgok/internal/cli/query_top"
 	"github.com/pg-ok/pgok/internal/cli/schema_owner"
+	"github.com/pg-ok/pgok/internal/cli/security_functions"
+	"github.com/pg-ok/pgok/internal/cli/security_grants"
+	"github.com/pg-ok/pgok/internal/cli/security_rls"
+	"github.com/pg-ok/pgok/internal/cli/security_roles"
 	"github.com/pg-ok/pgok/internal/cli/sequence_overflow"
+	"github.com/pg-ok/pgok/internal/cli/settings_audit"
+	"github.com/pg-ok/pgok/internal/cli/table_cache_hit"
+	"github.com/pg-ok/pgok/internal/cli/table_hot_updates"
 	"github.com/pg-ok/pgok/internal/cli/table_missing_pk"
+	"github.com/pg-ok/pgok/internal/cli/table_size"
+	"github.com/pg-ok/pgok/internal/cli/table_unused"
+	"github.com/pg-ok/pgok/internal/config"
 
 	"github.com/spf13/cobra"
 )
@@ -22,6 +42,21 @@ var rootCmd = &cobra.Command{
 	Use:   "pgok",
 	Short: "A CLI tool to analyze PG databases",
 	Long:  "pgok is a CLI utility for analyzing PostgreSQL database health, state, and performance.",
+
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		// Loaded once here, commands get the same config through config.Current()
+		cfg, err := config.Current()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		fromConfig, dbConfig := applyConfigDefaults(cmd, args, cfg)
+
+		if explain, err := cmd.Flags().GetBool("explain"); err == nil && explain {
+			printEffectiveOptions(cmd, fromConfig, dbConfig)
+		}
+	},
 }
 
 func Execute() {
@@ -31,13 +66,25 @@ func Execute() {
 }
 
 func init() {
+	rootCmd.PersistentFlags().StringVar(&config.Path, "config", "", "Config file (default: $PGOK_CONFIG or ./config/pgok.toml, $XDG_CONFIG_HOME/pgok/pgok.toml, /etc/pgok/pgok.toml merged)")
+
 	rootCmd.AddGroup(&cobra.Group{ID: "app", Title: "App Commands"})
+	rootCmd.AddGroup(&cobra.Group{ID: "column", Title: "Column Commands"})
+	rootCmd.AddGroup(&cobra.Group{ID: "constraint", Title: "Constraint Commands"})
 	rootCmd.AddGroup(&cobra.Group{ID: "index", Title: "Index Commands"})
+	rootCmd.AddGroup(&cobra.Group{ID: "partition", Title: "Partition Commands"})
+	rootCmd.AddGroup(&cobra.Group{ID: "query", Title: "Query Commands"})
 	rootCmd.AddGroup(&cobra.Group{ID: "schema", Title: "Schema Commands"})
+	rootCmd.AddGroup(&cobra.Group{ID: "security", Title: "Security Commands"})
 	rootCmd.AddGroup(&cobra.Group{ID: "sequence", Title: "Sequence Commands"})
+	rootCmd.AddGroup(&cobra.Group{ID: "settings", Title: "Settings Commands"})
 	rootCmd.AddGroup(&cobra.Group{ID: "table", Title: "Table Commands"})
 
+	rootCmd.AddCommand(app_config_show.NewCommand())
 	rootCmd.AddCommand(app_db_list.NewCommand())
+	rootCmd.AddCommand(column_int_overflow.NewCommand())
+	rootCmd.AddCommand(constraint_fk_type_mismatch.NewCommand())
+	rootCmd.AddCommand(constraint_not_valid.NewCommand())
 	rootCmd.AddCommand(index_cache_hit.NewCommand())
 	rootCmd.AddCommand(index_duplicate.NewCommand())
 	rootCmd.AddCommand(index_invalid.NewCommand())
@@ -45,7 +92,21 @@ func init() {
 	rootCmd.AddCommand(index_missing_fk.NewCommand())
 	rootCmd.AddCommand(index_size.NewCommand())
 	rootCmd.AddCommand(index_unused.NewCommand())
+	rootCmd.AddCommand(partition_default_missing.NewCommand())
+	rootCmd.AddCommand(partition_gaps.NewCommand())
+	rootCmd.AddCommand(partition_index_missing.NewCommand())
+	rootCmd.AddCommand(partition_skew.NewCommand())
+	rootCmd.AddCommand(query_top.NewCommand())
 	rootCmd.AddCommand(schema_owner.NewCommand())
+	rootCmd.AddCommand(security_functions.NewCommand())
+	rootCmd.AddCommand(security_grants.NewCommand())
+	rootCmd.AddCommand(security_rls.NewCommand())
+	rootCmd.AddCommand(security_roles.NewCommand())
 	rootCmd.AddCommand(sequence_overflow.NewCommand())
+	rootCmd.AddCommand(settings_audit.NewCommand())
+	rootCmd.AddCommand(table_cache_hit.NewCommand())
+	rootCmd.AddCommand(table_hot_updates.NewCommand())
 	rootCmd.AddCommand(table_missing_pk.NewCommand())
+	rootCmd.AddCommand(table_size.NewCommand())
+	rootCmd.AddCommand(table_unused.NewCommand())
 }
@@ -0,0 +1,43 @@
+package app_config_show
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/pg-ok/pgok/internal/config"
+
+	"github.com/BurntSushi/toml"
+	"github.com/spf13/cobra"
+)
+
+func NewCommand() *cobra.Command {
+	command := &cobra.Command{
+		GroupID: "app",
+
+		Use: "app:config:show",
+
+		Short: "Show the effective merged config (secrets masked)",
+
+		Run: func(cmd *cobra.Command, args []string) {
+			cfg, err := config.Current()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+
+			if len(cfg.Sources) == 0 {
+				fmt.Println("# No config files loaded")
+			}
+			for _, source := range cfg.Sources {
+				fmt.Printf("# Loaded: %s\n", source)
+			}
+
+			if err := toml.NewEncoder(os.Stdout).Encode(cfg.Masked()); err != nil {
+				fmt.Fprintf(os.Stderr, "Error encoding config: %v\n", err)
+				os.Exit(1)
+			}
+		},
+	}
+
+	return command
+}
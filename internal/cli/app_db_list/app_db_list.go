@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/pg-ok/pgok/internal/config"
 	"github.com/pg-ok/pgok/internal/db"
 
 	"github.com/spf13/cobra"
@@ -23,11 +24,16 @@ func NewCommand() *cobra.Command {
 
 			fmt.Println("Configured databases:")
 			for _, name := range names {
-				fmt.Printf("- %s\n", name)
+				dbConfig, _ := manager.GetConfigDatabase(name)
+				if len(dbConfig.Tags) > 0 {
+					fmt.Printf("- %s (%s)\n", name, config.FormatTags(dbConfig.Tags))
+				} else {
+					fmt.Printf("- %s\n", name)
+				}
 			}
 
 			if len(names) == 0 {
-				fmt.Fprintf(os.Stderr, "No databases found in config/pgok.toml\n")
+				fmt.Fprintf(os.Stderr, "No databases found in config (see app:config:show for loaded files)\n")
 			}
 		},
 	}
@@ -0,0 +1,395 @@
+package column_int_overflow
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/pg-ok/pgok/internal/db"
+	"github.com/pg-ok/pgok/internal/util"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+type Options struct {
+	DbName           string
+	Schema           string
+	Exclude          util.Exclusion
+	UsedMin          float64
+	Exact            bool
+	StatementTimeout string
+	Explain          bool
+	Output           util.OutputFormat
+}
+
+func NewCommand() *cobra.Command {
+	opts := &Options{
+		// Default to checking all schemas
+		Schema: "*",
+
+		UsedMin: 0.0,
+
+		StatementTimeout: "30s",
+
+		Output: util.OutputFormatTable,
+	}
+
+	command := &cobra.Command{
+		GroupID: "column",
+
+		Use: "column:int-overflow [db_name]",
+
+		Short: "Check smallint/integer key columns nearing their type limit",
+
+		Long: `Check smallint/integer key columns (PK, UNIQUE, FK and identity columns) nearing their type limit.
+Unlike sequence:overflow this also catches application-generated IDs and bigint sequences feeding integer columns.
+By default the current maximum is estimated from pg_stats; use --exact to run MAX() on every column.`,
+
+		Args: cobra.ExactArgs(1),
+
+		Run: func(cmd *cobra.Command, args []string) {
+			opts.DbName = args[0]
+			run(opts)
+		},
+	}
+
+	flags := command.Flags()
+	flags.StringVar(&opts.Schema, "schema", opts.Schema, "Schema name (use '*' for all user schemas)")
+	util.AddExclusionFlags(flags, &opts.Exclude)
+	flags.Float64Var(&opts.UsedMin, "used-percent-min", opts.UsedMin, "Filter columns by minimum used percentage of the type range (e.g. 80.0)")
+	flags.BoolVar(&opts.Exact, "exact", false, "Read the exact current maximum with SELECT MAX() instead of the pg_stats estimate")
+	flags.StringVar(&opts.StatementTimeout, "statement-timeout", opts.StatementTimeout, "Statement timeout for every exact MAX() query (used with --exact)")
+	flags.BoolVar(&opts.Explain, "explain", false, "Print the SQL query and explain the logic/interpretation")
+
+	flags.Var(&opts.Output, "output", "Output format (table, json)")
+	_ = command.RegisterFlagCompletionFunc("output", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{"table", "json"}, cobra.ShellCompDirectiveDefault
+	})
+
+	return command
+}
+
+type maxValueSource string
+
+const (
+	sourceStats maxValueSource = "stats"
+	sourceExact maxValueSource = "exact"
+	sourceNone  maxValueSource = "none"
+)
+
+type intOverflowRow struct {
+	Schema      string         `json:"schema"`
+	Table       string         `json:"table"`
+	Column      string         `json:"column"`
+	DataType    string         `json:"data_type"`
+	KeyRoles    []string       `json:"key_roles"`
+	CurrentMax  *int64         `json:"current_max"` // Pointer to handle NULL (no stats or empty table)
+	TypeMax     int64          `json:"type_max"`
+	UsedPercent float64        `json:"used_percent"`
+	Source      maxValueSource `json:"source"`
+}
+
+func run(opts *Options) {
+	manager := db.NewDbManager()
+
+	/*
+	 * Key columns = columns that are part of a PK, UNIQUE or FK constraint, or are identity columns.
+	 * The current maximum is estimated from the upper histogram bound and the most common values
+	 * collected by ANALYZE. For partitioned tables the statistics of all leaf partitions are aggregated.
+	 */
+	rawSql := `
+       WITH key_columns AS (
+          SELECT
+             relid,
+             attnum,
+             ARRAY_AGG(DISTINCT role ORDER BY role) AS key_roles
+          FROM (
+             SELECT
+                c.conrelid AS relid,
+                k.attnum,
+                CASE c.contype
+                   WHEN 'p' THEN 'PK'
+                   WHEN 'u' THEN 'UNIQUE'
+                   WHEN 'f' THEN 'FK'
+                END AS role
+             FROM pg_constraint AS c
+             CROSS JOIN LATERAL UNNEST(c.conkey) AS k(attnum)
+             WHERE c.contype IN ('p', 'u', 'f')
+
+             UNION ALL
+
+             SELECT
+                a.attrelid AS relid,
+                a.attnum,
+                'IDENTITY' AS role
+             FROM pg_attribute AS a
+             WHERE a.attidentity <> ''
+          ) AS roles
+          GROUP BY relid, attnum
+       )
+       SELECT
+          n.nspname AS schema_name,
+          cl.relname AS table_name,
+          a.attname AS column_name,
+          format_type(a.atttypid, a.atttypmod) AS data_type,
+          kc.key_roles,
+          stats.max_value AS estimated_max,
+          CASE a.atttypid
+             WHEN 'int2'::REGTYPE THEN 32767
+             ELSE 2147483647
+          END::BIGINT AS type_max
+       FROM key_columns AS kc
+       JOIN pg_attribute AS a
+         ON a.attrelid = kc.relid
+        AND a.attnum = kc.attnum
+       JOIN pg_class AS cl
+         ON cl.oid = a.attrelid
+       JOIN pg_namespace AS n
+         ON n.oid = cl.relnamespace
+       LEFT JOIN LATERAL (
+          SELECT MAX(GREATEST(
+             (SELECT MAX(v) FROM UNNEST(s.histogram_bounds::TEXT::BIGINT[]) AS v),
+             (SELECT MAX(v) FROM UNNEST(s.most_common_vals::TEXT::BIGINT[]) AS v)
+          )) AS max_value
+          FROM (
+             -- Autovacuum never analyzes partitioned tables, so the leaf partitions are read as well
+             SELECT cl.oid AS relid
+             UNION
+             SELECT pt.relid
+             FROM pg_partition_tree(cl.oid) AS pt
+             WHERE cl.relkind = 'p'
+               AND pt.isleaf
+          ) AS rel
+          JOIN pg_class AS pc
+            ON pc.oid = rel.relid
+          JOIN pg_namespace AS pn
+            ON pn.oid = pc.relnamespace
+          JOIN pg_stats AS s
+            ON s.schemaname = pn.nspname
+           AND s.tablename = pc.relname
+           AND s.attname = a.attname
+       ) AS stats ON true
+       WHERE
+          ($1 = '*' OR n.nspname = $1)
+          AND n.nspname NOT IN ('pg_catalog', 'information_schema')
+          AND n.nspname NOT LIKE 'pg_toast%'
+          AND NOT (n.nspname = ANY($2::TEXT[]))
+          AND NOT (cl.relname = ANY($3::TEXT[]) OR n.nspname || '.' || cl.relname = ANY($3::TEXT[]))
+          AND cl.relkind IN ('r', 'p')
+          AND NOT cl.relispartition -- Partitions are covered by their parent
+          AND NOT a.attisdropped
+          AND a.atttypid IN ('int2'::REGTYPE, 'int4'::REGTYPE)
+       ORDER BY schema_name, table_name, column_name;
+    `
+
+	exactSqlTemplate := "SELECT MAX(%s)::BIGINT FROM %s;"
+
+	sqlQuery := util.TrimLeftSpaces(rawSql)
+
+	if opts.Explain {
+		printExplanation(sqlQuery, exactSqlTemplate, opts)
+		return
+	}
+
+	ctx := context.Background()
+	conn, err := manager.Connect(ctx, opts.DbName)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error connecting to database: %v\n", err)
+		os.Exit(1)
+	}
+	defer func(conn *pgx.Conn, ctx context.Context) {
+		err := conn.Close(ctx)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error closing connection: %v\n", err)
+		}
+	}(conn, ctx)
+
+	rows, err := conn.Query(ctx, sqlQuery, opts.Schema, opts.Exclude.Schemas, opts.Exclude.Tables)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Query failed: %v\n", err)
+		os.Exit(1)
+	}
+
+	var candidates []intOverflowRow
+
+	for rows.Next() {
+		var r intOverflowRow
+
+		err := rows.Scan(
+			&r.Schema,
+			&r.Table,
+			&r.Column,
+			&r.DataType,
+			&r.KeyRoles,
+			&r.CurrentMax,
+			&r.TypeMax,
+		)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Row scan failed: %v\n", err)
+			os.Exit(1)
+		}
+
+		r.Source = sourceStats
+		if r.CurrentMax == nil {
+			r.Source = sourceNone
+		}
+
+		candidates = append(candidates, r)
+	}
+	rows.Close()
+
+	if rows.Err() != nil {
+		fmt.Fprintf(os.Stderr, "Rows iteration failed: %v\n", rows.Err())
+		os.Exit(1)
+	}
+
+	if opts.Exact {
+		if _, err := conn.Exec(ctx, "SELECT set_config('statement_timeout', $1, false)", opts.StatementTimeout); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to set statement timeout: %v\n", err)
+			os.Exit(1)
+		}
+
+		for i := range candidates {
+			r := &candidates[i]
+
+			exactSql := fmt.Sprintf(
+				exactSqlTemplate,
+				pgx.Identifier{r.Column}.Sanitize(),
+				pgx.Identifier{r.Schema, r.Table}.Sanitize(),
+			)
+
+			var exactMax *int64
+			if err := conn.QueryRow(ctx, exactSql).Scan(&exactMax); err != nil {
+				// Keep the estimate, a single slow table should not abort the whole check
+				fmt.Fprintf(os.Stderr, "Warning: Exact MAX() failed for %s.%s.%s, using estimate: %v\n", r.Schema, r.Table, r.Column, err)
+				continue
+			}
+
+			r.CurrentMax = exactMax
+			r.Source = sourceExact
+		}
+	}
+
+	results := []intOverflowRow{}
+
+	for _, r := range candidates {
+		if r.CurrentMax != nil {
+			r.UsedPercent = float64(*r.CurrentMax) / float64(r.TypeMax) * 100.0
+		}
+
+		if r.UsedPercent < opts.UsedMin {
+			continue
+		}
+
+		results = append(results, r)
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].UsedPercent > results[j].UsedPercent
+	})
+
+	switch opts.Output {
+	case util.OutputFormatJson:
+		jsonData, _ := json.MarshalIndent(results, "", "  ")
+		fmt.Println(string(jsonData))
+
+	default:
+		schemaDisplay := opts.Schema
+		if opts.Schema == "*" {
+			schemaDisplay = "ALL (except system)"
+		}
+
+		modeDisplay := "estimate (pg_stats)"
+		if opts.Exact {
+			modeDisplay = fmt.Sprintf("exact (MAX(), timeout %s)", opts.StatementTimeout)
+		}
+
+		fmt.Printf("Checking integer key columns overflow in `%s`\n", opts.DbName)
+		fmt.Printf("Schema: %s, Mode: %s\n", schemaDisplay, modeDisplay)
+
+		if len(results) == 0 {
+			fmt.Println(strings.Repeat("-", 80))
+			fmt.Println("No smallint/integer key columns found within the specified criteria.")
+			return
+		}
+
+		table := tablewriter.NewWriter(os.Stdout)
+		table.Header([]string{"Schema", "Table", "Column", "Type", "Key", "Used % (Current / Max)"})
+
+		for _, row := range results {
+			usageDisplay := "n/a (no stats)"
+			if row.Source == sourceExact && row.CurrentMax == nil {
+				// MAX() found no values: the table is empty (or the column only holds NULLs)
+				usageDisplay = "empty"
+			} else if row.CurrentMax != nil {
+				usedPercentDisplay := fmt.Sprintf("%.2f%%", row.UsedPercent)
+				if row.UsedPercent > 80.0 {
+					usedPercentDisplay += " [!]"
+				}
+
+				usageDisplay = fmt.Sprintf("%s (%d / %d)", usedPercentDisplay, *row.CurrentMax, row.TypeMax)
+				if row.Source == sourceStats && opts.Exact {
+					usageDisplay += " ~"
+				}
+			}
+
+			err := table.Append([]string{
+				row.Schema,
+				row.Table,
+				row.Column,
+				row.DataType,
+				strings.Join(row.KeyRoles, ", "),
+				usageDisplay,
+			})
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error appending table row: %v\n", err)
+			}
+		}
+		if err := table.Render(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error rendering table: %v\n", err)
+		}
+
+		fmt.Println(strings.Repeat("-", 115))
+		fmt.Println("* [!] indicates columns nearing the type limit (>80%). INT overflow risk!")
+		if opts.Exact {
+			fmt.Println("* ~ = Exact MAX() failed (e.g. timeout), the value is estimated from pg_stats.")
+		} else {
+			fmt.Println("* Values are estimated from pg_stats (as of the last ANALYZE). Use --exact for precise numbers.")
+		}
+	}
+}
+
+func printExplanation(sqlQuery string, exactSqlTemplate string, opts *Options) {
+	fmt.Println("📖 EXPLANATION")
+	fmt.Println("-------------")
+	fmt.Println("smallint and integer columns overflow at 32,767 and 2,147,483,647.")
+	fmt.Println("Key columns are not always filled by a sequence: IDs may be generated by the application,")
+	fmt.Println("or a bigint sequence may feed an integer column. sequence:overflow cannot see these cases.")
+	fmt.Println("")
+
+	fmt.Println("🧠 INTERPRETATION")
+	fmt.Println("-----------------")
+	fmt.Println("• Used %: Current maximum value divided by the maximum of the column type.")
+	fmt.Println("• Estimate: The highest value in pg_stats histogram/most common values (as of the last ANALYZE).")
+	fmt.Println("  It can lag behind the real maximum on fast growing tables.")
+	fmt.Println("• Partitioned tables: Reported once on the parent, the estimate is the highest value over all leaf partitions.")
+	fmt.Println("• Exact: --exact runs SELECT MAX() per column. It uses an index when there is one,")
+	fmt.Println("  otherwise it scans the table, so every query is limited by --statement-timeout.")
+	fmt.Println("• Risk: If > 80-90%, plan a migration to BIGINT (including all referencing FK columns).")
+	fmt.Println("")
+
+	fmt.Println("💻 SQL QUERY")
+	fmt.Println("------------")
+	util.PrintRunnableSQL(sqlQuery, []interface{}{opts.Schema, opts.Exclude.Schemas, opts.Exclude.Tables})
+
+	if opts.Exact {
+		fmt.Println("")
+		fmt.Printf("-- Exact mode, for every column (statement_timeout = %s):\n", opts.StatementTimeout)
+		fmt.Printf(exactSqlTemplate+"\n", "<column>", "<schema>.<table>")
+	}
+}
@@ -0,0 +1,261 @@
+package constraint_fk_type_mismatch
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/pg-ok/pgok/internal/db"
+	"github.com/pg-ok/pgok/internal/util"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+type Options struct {
+	DbName  string
+	Schema  string
+	Exclude util.Exclusion
+	Explain bool
+	Output  util.OutputFormat
+}
+
+func NewCommand() *cobra.Command {
+	opts := &Options{
+		// Default to scanning all schemas
+		Schema: "*",
+
+		Output: util.OutputFormatTable,
+	}
+
+	command := &cobra.Command{
+		GroupID: "constraint",
+
+		Use: "constraint:fk-type-mismatch [db_name]",
+
+		Short: "Find foreign keys whose column types differ from the referenced columns",
+
+		Long: `Find foreign keys whose column types differ from the referenced columns.
+Mismatched types (e.g. integer -> bigint, varchar -> text), typmods or collations force casts
+on every integrity check and join, and can prevent the planner from using indexes.`,
+
+		Args: cobra.ExactArgs(1),
+
+		Run: func(cmd *cobra.Command, args []string) {
+			opts.DbName = args[0]
+			run(opts)
+		},
+	}
+
+	flags := command.Flags()
+	flags.StringVar(&opts.Schema, "schema", opts.Schema, "Schema name (use '*' for all user schemas)")
+	util.AddExclusionFlags(flags, &opts.Exclude)
+	flags.BoolVar(&opts.Explain, "explain", false, "Print the SQL query and explain the logic/interpretation")
+
+	flags.Var(&opts.Output, "output", "Output format (table, json)")
+	_ = command.RegisterFlagCompletionFunc("output", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{"table", "json"}, cobra.ShellCompDirectiveDefault
+	})
+
+	return command
+}
+
+type fkTypeMismatchRow struct {
+	Schema              string   `json:"schema"`
+	Table               string   `json:"table"`
+	ForeignKey          string   `json:"foreign_key"`
+	Column              string   `json:"column"`
+	ColumnType          string   `json:"column_type"`
+	ColumnCollation     string   `json:"column_collation"`
+	ReferencedTable     string   `json:"referenced_table"`
+	ReferencedColumn    string   `json:"referenced_column"`
+	ReferencedType      string   `json:"referenced_type"`
+	ReferencedCollation string   `json:"referenced_collation"`
+	Mismatches          []string `json:"mismatches"`
+}
+
+func run(opts *Options) {
+	manager := db.NewDbManager()
+
+	/*
+	 * This SQL query walks every Foreign Key column pair (conkey[i] -> confkey[i])
+	 * and compares type OID, typmod and collation of both sides.
+	 */
+	rawSql := `
+       SELECT
+          n.nspname AS schema_name,
+          cl.relname AS table_name,
+          c.conname AS foreign_key,
+          a.attname AS column_name,
+          format_type(a.atttypid, a.atttypmod) AS column_type,
+          COALESCE(co.collname, '') AS column_collation,
+          rn.nspname || '.' || rcl.relname AS referenced_table,
+          ra.attname AS referenced_column,
+          format_type(ra.atttypid, ra.atttypmod) AS referenced_type,
+          COALESCE(rco.collname, '') AS referenced_collation,
+          ARRAY_REMOVE(ARRAY[
+             CASE WHEN a.atttypid <> ra.atttypid THEN 'type' END,
+             CASE WHEN a.atttypid = ra.atttypid AND a.atttypmod <> ra.atttypmod THEN 'typmod' END,
+             CASE WHEN a.attcollation <> ra.attcollation THEN 'collation' END
+          ], NULL) AS mismatches
+       FROM pg_constraint AS c
+       JOIN pg_namespace AS n ON n.oid = c.connamespace
+       JOIN pg_class AS cl ON cl.oid = c.conrelid
+       JOIN pg_class AS rcl ON rcl.oid = c.confrelid
+       JOIN pg_namespace AS rn ON rn.oid = rcl.relnamespace
+       -- Pair FK columns with referenced columns by position
+       CROSS JOIN LATERAL UNNEST(c.conkey, c.confkey) AS k(attnum, ref_attnum)
+       JOIN pg_attribute AS a ON a.attrelid = c.conrelid AND a.attnum = k.attnum
+       JOIN pg_attribute AS ra ON ra.attrelid = c.confrelid AND ra.attnum = k.ref_attnum
+       LEFT JOIN pg_collation AS co ON co.oid = a.attcollation
+       LEFT JOIN pg_collation AS rco ON rco.oid = ra.attcollation
+       WHERE c.contype = 'f' -- Only Foreign Keys
+       AND c.conparentid = 0 -- Skip FKs cloned onto partitions from the partitioned parent
+       AND ($1 = '*' OR n.nspname = $1)
+       AND n.nspname NOT IN ('pg_catalog', 'information_schema')
+       AND n.nspname NOT LIKE 'pg_toast%'
+       AND NOT (n.nspname = ANY($2::TEXT[]))
+       AND NOT (cl.relname = ANY($3::TEXT[]) OR n.nspname || '.' || cl.relname = ANY($3::TEXT[]))
+       AND (
+          a.atttypid <> ra.atttypid
+          OR a.atttypmod <> ra.atttypmod
+          OR a.attcollation <> ra.attcollation
+       )
+       ORDER BY schema_name, table_name, foreign_key, column_name;
+    `
+
+	sqlQuery := util.TrimLeftSpaces(rawSql)
+
+	if opts.Explain {
+		printExplanation(sqlQuery, opts)
+		return
+	}
+
+	ctx := context.Background()
+	conn, err := manager.Connect(ctx, opts.DbName)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error connecting to database: %v\n", err)
+		os.Exit(1)
+	}
+	defer func(conn *pgx.Conn, ctx context.Context) {
+		err := conn.Close(ctx)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error closing connection: %v\n", err)
+		}
+	}(conn, ctx)
+
+	rows, err := conn.Query(ctx, sqlQuery, opts.Schema, opts.Exclude.Schemas, opts.Exclude.Tables)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Query failed: %v\n", err)
+		os.Exit(1)
+	}
+	defer rows.Close()
+
+	var results []fkTypeMismatchRow
+
+	for rows.Next() {
+		var r fkTypeMismatchRow
+
+		err := rows.Scan(
+			&r.Schema,
+			&r.Table,
+			&r.ForeignKey,
+			&r.Column,
+			&r.ColumnType,
+			&r.ColumnCollation,
+			&r.ReferencedTable,
+			&r.ReferencedColumn,
+			&r.ReferencedType,
+			&r.ReferencedCollation,
+			&r.Mismatches,
+		)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Row scan failed: %v\n", err)
+			os.Exit(1)
+		}
+
+		results = append(results, r)
+	}
+
+	if rows.Err() != nil {
+		fmt.Fprintf(os.Stderr, "Rows iteration failed: %v\n", rows.Err())
+		os.Exit(1)
+	}
+
+	switch opts.Output {
+	case util.OutputFormatJson:
+		jsonData, _ := json.MarshalIndent(results, "", "  ")
+		fmt.Println(string(jsonData))
+
+	default:
+		schemaDisplay := opts.Schema
+		if opts.Schema == "*" {
+			schemaDisplay = "ALL (except system)"
+		}
+
+		fmt.Printf("Searching for Foreign Key column type mismatches in `%s`\n", opts.DbName)
+		fmt.Printf("Schema: %s\n", schemaDisplay)
+
+		if len(results) == 0 {
+			fmt.Println(strings.Repeat("-", 80))
+			fmt.Println("All Foreign Key columns match the referenced column types. 👍")
+			return
+		}
+
+		table := tablewriter.NewWriter(os.Stdout)
+		table.Header([]string{"Schema", "Table", "Foreign Key", "Column", "References", "Mismatch"})
+
+		for _, row := range results {
+			err := table.Append([]string{
+				row.Schema,
+				row.Table,
+				row.ForeignKey,
+				formatColumn(row.Column, row.ColumnType, row.ColumnCollation),
+				formatColumn(row.ReferencedTable+"."+row.ReferencedColumn, row.ReferencedType, row.ReferencedCollation),
+				strings.Join(row.Mismatches, ", "),
+			})
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error appending table row: %v\n", err)
+			}
+		}
+		if err := table.Render(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error rendering table: %v\n", err)
+		}
+
+		fmt.Println(strings.Repeat("-", 80))
+		fmt.Println("* Tip: Align the referencing column with the referenced one (ALTER TABLE ... ALTER COLUMN ... TYPE ...).")
+	}
+}
+
+// formatColumn renders a column with its type and (non-default) collation, e.g. "email varchar(255) COLLATE C".
+func formatColumn(name string, dataType string, collation string) string {
+	s := name + " " + dataType
+	if collation != "" && collation != "default" {
+		s += " COLLATE " + collation
+	}
+	return s
+}
+
+func printExplanation(sqlQuery string, opts *Options) {
+	fmt.Println("📖 EXPLANATION")
+	fmt.Println("-------------")
+	fmt.Println("PostgreSQL allows a Foreign Key column to have a different (but comparable) type")
+	fmt.Println("than the referenced column, e.g. integer -> bigint or varchar -> text.")
+	fmt.Println("Every referential check and every join between the tables then requires a cast.")
+	fmt.Println("")
+
+	fmt.Println("🧠 INTERPRETATION")
+	fmt.Println("-----------------")
+	fmt.Println("• type: Different data types. Casts may prevent index usage on one side of the join.")
+	fmt.Println("• typmod: Same type with different modifiers (e.g. varchar(50) vs varchar(255), numeric(10,2) vs numeric).")
+	fmt.Println("• collation: Text comparisons use different collations, which can also prevent index usage.")
+	fmt.Println("• Action: Change the referencing column to exactly match the referenced column definition.")
+	fmt.Println("")
+
+	fmt.Println("💻 SQL QUERY")
+	fmt.Println("------------")
+	util.PrintRunnableSQL(sqlQuery, []interface{}{opts.Schema, opts.Exclude.Schemas, opts.Exclude.Tables})
+}
@@ -0,0 +1,336 @@
+package constraint_not_valid
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/pg-ok/pgok/internal/db"
+	"github.com/pg-ok/pgok/internal/util"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+type Options struct {
+	DbName  string
+	Schema  string
+	Exclude util.Exclusion
+	Explain bool
+	Output  util.OutputFormat
+}
+
+func NewCommand() *cobra.Command {
+	opts := &Options{
+		// Default to scanning all schemas
+		Schema: "*",
+
+		Output: util.OutputFormatTable,
+	}
+
+	command := &cobra.Command{
+		GroupID: "constraint",
+
+		Use: "constraint:not-valid [db_name]",
+
+		Short: "Find constraints left NOT VALID and disabled triggers",
+
+		Long: `Find CHECK and FOREIGN KEY constraints left NOT VALID and triggers that are disabled.
+Constraints added with NOT VALID during migrations are not guaranteed for existing rows until validated,
+and disabled triggers (including FK system triggers) mean integrity is not being enforced at all.`,
+
+		Args: cobra.ExactArgs(1),
+
+		Run: func(cmd *cobra.Command, args []string) {
+			opts.DbName = args[0]
+			run(opts)
+		},
+	}
+
+	flags := command.Flags()
+	flags.StringVar(&opts.Schema, "schema", opts.Schema, "Schema name (use '*' for all user schemas)")
+	util.AddExclusionFlags(flags, &opts.Exclude)
+	flags.BoolVar(&opts.Explain, "explain", false, "Print the SQL query and explain the logic/interpretation")
+
+	flags.Var(&opts.Output, "output", "Output format (table, json)")
+	_ = command.RegisterFlagCompletionFunc("output", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{"table", "json"}, cobra.ShellCompDirectiveDefault
+	})
+
+	return command
+}
+
+type notValidConstraintRow struct {
+	Schema         string `json:"schema"`
+	Table          string `json:"table"`
+	Constraint     string `json:"constraint"`
+	ConstraintType string `json:"constraint_type"`
+	Definition     string `json:"definition"`
+	TableSizeHuman string `json:"table_size_human"`
+	TableSizeBytes int64  `json:"table_size_bytes"`
+}
+
+type disabledTriggerRow struct {
+	Schema     string `json:"schema"`
+	Table      string `json:"table"`
+	Trigger    string `json:"trigger"`
+	IsInternal bool   `json:"is_internal"`
+	Constraint string `json:"constraint"`
+}
+
+type notValidResult struct {
+	Constraints []notValidConstraintRow `json:"constraints"`
+	Triggers    []disabledTriggerRow    `json:"triggers"`
+}
+
+func run(opts *Options) {
+	manager := db.NewDbManager()
+
+	rawConstraintsSql := `
+       SELECT
+          n.nspname AS schema_name,
+          cl.relname AS table_name,
+          c.conname AS constraint_name,
+          CASE c.contype
+             WHEN 'c' THEN 'CHECK'
+             WHEN 'f' THEN 'FOREIGN KEY'
+          END AS constraint_type,
+          pg_get_constraintdef(c.oid) AS definition,
+          pg_size_pretty(pg_table_size(c.conrelid)) AS table_size_human,
+          pg_table_size(c.conrelid) AS table_size_bytes
+       FROM pg_constraint AS c
+       JOIN pg_namespace AS n ON n.oid = c.connamespace
+       JOIN pg_class AS cl ON cl.oid = c.conrelid
+       WHERE c.contype IN ('c', 'f')
+       AND c.convalidated = false
+       AND ($1 = '*' OR n.nspname = $1)
+       AND n.nspname NOT IN ('pg_catalog', 'information_schema')
+       AND n.nspname NOT LIKE 'pg_toast%'
+       AND NOT (n.nspname = ANY($2::TEXT[]))
+       AND NOT (cl.relname = ANY($3::TEXT[]) OR n.nspname || '.' || cl.relname = ANY($3::TEXT[]))
+       ORDER BY table_size_bytes DESC, schema_name, table_name, constraint_name;
+    `
+
+	// tgisinternal marks system triggers, e.g. the RI_ConstraintTrigger_* ones enforcing Foreign Keys
+	rawTriggersSql := `
+       SELECT
+          n.nspname AS schema_name,
+          cl.relname AS table_name,
+          t.tgname AS trigger_name,
+          t.tgisinternal AS is_internal,
+          COALESCE(c.conname, '') AS constraint_name
+       FROM pg_trigger AS t
+       JOIN pg_class AS cl ON cl.oid = t.tgrelid
+       JOIN pg_namespace AS n ON n.oid = cl.relnamespace
+       LEFT JOIN pg_constraint AS c ON c.oid = t.tgconstraint
+       WHERE t.tgenabled = 'D'
+       AND ($1 = '*' OR n.nspname = $1)
+       AND n.nspname NOT IN ('pg_catalog', 'information_schema')
+       AND n.nspname NOT LIKE 'pg_toast%'
+       AND NOT (n.nspname = ANY($2::TEXT[]))
+       AND NOT (cl.relname = ANY($3::TEXT[]) OR n.nspname || '.' || cl.relname = ANY($3::TEXT[]))
+       ORDER BY schema_name, table_name, trigger_name;
+    `
+
+	constraintsSqlQuery := util.TrimLeftSpaces(rawConstraintsSql)
+	triggersSqlQuery := util.TrimLeftSpaces(rawTriggersSql)
+
+	if opts.Explain {
+		printExplanation(constraintsSqlQuery, triggersSqlQuery, opts)
+		return
+	}
+
+	ctx := context.Background()
+	conn, err := manager.Connect(ctx, opts.DbName)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error connecting to database: %v\n", err)
+		os.Exit(1)
+	}
+	defer func(conn *pgx.Conn, ctx context.Context) {
+		err := conn.Close(ctx)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error closing connection: %v\n", err)
+		}
+	}(conn, ctx)
+
+	result := notValidResult{
+		Constraints: queryConstraints(ctx, conn, constraintsSqlQuery, opts),
+		Triggers:    queryTriggers(ctx, conn, triggersSqlQuery, opts),
+	}
+
+	switch opts.Output {
+	case util.OutputFormatJson:
+		jsonData, _ := json.MarshalIndent(result, "", "  ")
+		fmt.Println(string(jsonData))
+
+	default:
+		schemaDisplay := opts.Schema
+		if opts.Schema == "*" {
+			schemaDisplay = "ALL (except system)"
+		}
+
+		fmt.Printf("Searching for NOT VALID constraints and disabled triggers in `%s`\n", opts.DbName)
+		fmt.Printf("Schema: %s\n", schemaDisplay)
+
+		if len(result.Constraints) == 0 && len(result.Triggers) == 0 {
+			fmt.Println(strings.Repeat("-", 80))
+			fmt.Println("All constraints are validated and all triggers are enabled. 🔒")
+			return
+		}
+
+		if len(result.Constraints) > 0 {
+			fmt.Println("\nNOT VALID constraints:")
+
+			table := tablewriter.NewWriter(os.Stdout)
+			table.Header([]string{"Schema", "Table", "Table Size", "Constraint", "Type"})
+
+			for _, row := range result.Constraints {
+				err := table.Append([]string{
+					row.Schema,
+					row.Table,
+					row.TableSizeHuman,
+					row.Constraint,
+					row.ConstraintType,
+				})
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Error appending table row: %v\n", err)
+				}
+			}
+			if err := table.Render(); err != nil {
+				fmt.Fprintf(os.Stderr, "Error rendering table: %v\n", err)
+			}
+		}
+
+		if len(result.Triggers) > 0 {
+			fmt.Println("\nDisabled triggers:")
+
+			table := tablewriter.NewWriter(os.Stdout)
+			table.Header([]string{"Schema", "Table", "Trigger", "Constraint"})
+
+			for _, row := range result.Triggers {
+				triggerDisplay := row.Trigger
+				if row.IsInternal {
+					triggerDisplay += " [SYSTEM]"
+				}
+
+				err := table.Append([]string{
+					row.Schema,
+					row.Table,
+					triggerDisplay,
+					row.Constraint,
+				})
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Error appending table row: %v\n", err)
+				}
+			}
+			if err := table.Render(); err != nil {
+				fmt.Fprintf(os.Stderr, "Error rendering table: %v\n", err)
+			}
+		}
+
+		fmt.Println(strings.Repeat("-", 80))
+		fmt.Println("* Run ALTER TABLE ... VALIDATE CONSTRAINT ... to validate existing rows (takes only a SHARE UPDATE EXCLUSIVE lock).")
+		fmt.Println("* [SYSTEM] = Internal trigger enforcing a Foreign Key. When disabled, the FK is NOT enforced.")
+	}
+}
+
+func queryConstraints(ctx context.Context, conn *pgx.Conn, sqlQuery string, opts *Options) []notValidConstraintRow {
+	rows, err := conn.Query(ctx, sqlQuery, opts.Schema, opts.Exclude.Schemas, opts.Exclude.Tables)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Query failed: %v\n", err)
+		os.Exit(1)
+	}
+	defer rows.Close()
+
+	results := []notValidConstraintRow{}
+
+	for rows.Next() {
+		var r notValidConstraintRow
+
+		err := rows.Scan(
+			&r.Schema,
+			&r.Table,
+			&r.Constraint,
+			&r.ConstraintType,
+			&r.Definition,
+			&r.TableSizeHuman,
+			&r.TableSizeBytes,
+		)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Row scan failed: %v\n", err)
+			os.Exit(1)
+		}
+
+		results = append(results, r)
+	}
+
+	if rows.Err() != nil {
+		fmt.Fprintf(os.Stderr, "Rows iteration failed: %v\n", rows.Err())
+		os.Exit(1)
+	}
+
+	return results
+}
+
+func queryTriggers(ctx context.Context, conn *pgx.Conn, sqlQuery string, opts *Options) []disabledTriggerRow {
+	rows, err := conn.Query(ctx, sqlQuery, opts.Schema, opts.Exclude.Schemas, opts.Exclude.Tables)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Query failed: %v\n", err)
+		os.Exit(1)
+	}
+	defer rows.Close()
+
+	results := []disabledTriggerRow{}
+
+	for rows.Next() {
+		var r disabledTriggerRow
+
+		err := rows.Scan(
+			&r.Schema,
+			&r.Table,
+			&r.Trigger,
+			&r.IsInternal,
+			&r.Constraint,
+		)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Row scan failed: %v\n", err)
+			os.Exit(1)
+		}
+
+		results = append(results, r)
+	}
+
+	if rows.Err() != nil {
+		fmt.Fprintf(os.Stderr, "Rows iteration failed: %v\n", rows.Err())
+		os.Exit(1)
+	}
+
+	return results
+}
+
+func printExplanation(constraintsSqlQuery string, triggersSqlQuery string, opts *Options) {
+	fmt.Println("📖 EXPLANATION")
+	fmt.Println("-------------")
+	fmt.Println("Constraints can be added with NOT VALID to avoid a long lock while existing rows are checked.")
+	fmt.Println("New rows are checked immediately, but existing rows are NOT until VALIDATE CONSTRAINT is run.")
+	fmt.Println("This second step is often forgotten after a migration.")
+	fmt.Println("")
+
+	fmt.Println("🧠 INTERPRETATION")
+	fmt.Println("-----------------")
+	fmt.Println("• NOT VALID: Existing rows may violate the constraint. The planner also cannot rely on it.")
+	fmt.Println("• Table Size: Bigger tables take longer to validate, plan the VALIDATE pass accordingly.")
+	fmt.Println("• Disabled trigger: ALTER TABLE ... DISABLE TRIGGER was used (often for bulk loads) and never re-enabled.")
+	fmt.Println("• [SYSTEM] trigger: Foreign Keys are enforced by internal triggers, disabling them disables the FK.")
+	fmt.Println("• Action: Run ALTER TABLE ... VALIDATE CONSTRAINT ... and ALTER TABLE ... ENABLE TRIGGER ...")
+	fmt.Println("")
+
+	fmt.Println("💻 SQL QUERY")
+	fmt.Println("------------")
+	util.PrintRunnableSQL(constraintsSqlQuery, []interface{}{opts.Schema, opts.Exclude.Schemas, opts.Exclude.Tables})
+	fmt.Println("")
+	util.PrintRunnableSQL(triggersSqlQuery, []interface{}{opts.Schema, opts.Exclude.Schemas, opts.Exclude.Tables})
+}
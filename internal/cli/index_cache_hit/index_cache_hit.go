@@ -18,6 +18,7 @@ import (
 type Options struct {
 	DbName   string
 	Schema   string
+	Exclude  util.Exclusion
 	CallsMin int64
 	Explain  bool
 	Output   util.OutputFormat
@@ -50,6 +51,7 @@ func NewCommand() *cobra.Command {
 
 	flags := command.Flags()
 	flags.StringVar(&opts.Schema, "schema", opts.Schema, "Schema name (use '*' for all user schemas)")
+	util.AddExclusionFlags(flags, &opts.Exclude)
 	flags.Int64Var(&opts.CallsMin, "calls-min", opts.CallsMin, "Minimum total block accesses (hits + reads) to include")
 	flags.BoolVar(&opts.Explain, "explain", false, "Print the SQL query and explain the logic/interpretation")
 
@@ -108,6 +110,8 @@ func run(opts *Options) {
          ($1 = '*' OR s.schemaname = $1)
          AND s.schemaname NOT IN ('pg_catalog', 'information_schema')
          AND s.schemaname NOT LIKE 'pg_toast%'
+         AND NOT (s.schemaname = ANY($3::TEXT[]))
+         AND NOT (s.relname = ANY($4::TEXT[]) OR s.schemaname || '.' || s.relname = ANY($4::TEXT[]))
          
        AND (s.idx_blks_hit + s.idx_blks_read) >= $2
        ORDER BY hit_ratio ASC;
@@ -133,7 +137,7 @@ func run(opts *Options) {
 		}
 	}(conn, ctx)
 
-	rows, err := conn.Query(ctx, sqlQuery, opts.Schema, opts.CallsMin)
+	rows, err := conn.Query(ctx, sqlQuery, opts.Schema, opts.CallsMin, opts.Exclude.Schemas, opts.Exclude.Tables)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Query failed: %v\n", err)
 		os.Exit(1)
@@ -255,5 +259,5 @@ func printExplanation(sqlQuery string, opts *Options) {
 
 	fmt.Println("💻 SQL QUERY")
 	fmt.Println("------------")
-	util.PrintRunnableSQL(sqlQuery, []interface{}{opts.Schema, opts.CallsMin})
+	util.PrintRunnableSQL(sqlQuery, []interface{}{opts.Schema, opts.CallsMin, opts.Exclude.Schemas, opts.Exclude.Tables})
 }
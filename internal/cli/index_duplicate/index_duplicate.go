@@ -18,6 +18,7 @@ import (
 type Options struct {
 	DbName  string
 	Schema  string
+	Exclude util.Exclusion
 	Explain bool
 	Output  util.OutputFormat
 }
@@ -47,6 +48,7 @@ func NewCommand() *cobra.Command {
 
 	flags := command.Flags()
 	flags.StringVar(&opts.Schema, "schema", opts.Schema, "Schema name (use '*' for all user schemas)")
+	util.AddExclusionFlags(flags, &opts.Exclude)
 	flags.BoolVar(&opts.Explain, "explain", false, "Print the SQL query and explain the logic/interpretation")
 
 	flags.Var(&opts.Output, "output", "Output format (table, json)")
@@ -57,32 +59,71 @@ func NewCommand() *cobra.Command {
 	return command
 }
 
+type duplicateCandidate struct {
+	Index          string `json:"index"`
+	SizeHuman      string `json:"size_human"`
+	SizeBytes      int64  `json:"size_bytes"`
+	Scans          int64  `json:"scans"`
+	ConstraintRole string `json:"constraint_role"`
+}
+
 type duplicateRow struct {
-	Schema      string   `json:"schema"`
-	SizeHuman   string   `json:"size_human"`
-	SizeBytes   int64    `json:"size_bytes"`
-	KeepIndex   string   `json:"keep_index"`
-	DropIndexes []string `json:"drop_indexes"`
+	Schema      string               `json:"schema"`
+	Table       string               `json:"table"`
+	SizeHuman   string               `json:"size_human"`
+	SizeBytes   int64                `json:"size_bytes"`
+	KeepIndex   string               `json:"keep_index"`
+	DropIndexes []string             `json:"drop_indexes"`
+	Candidates  []duplicateCandidate `json:"candidates"`
 }
 
 func run(opts *Options) {
 	manager := db.NewDbManager()
 
+	/*
+	 * Candidates inside a group are ordered by how hard they are to drop:
+	 * PK > UNIQUE/EXCLUSION constraint > UNIQUE index > REPLICA IDENTITY > referenced by FK > oldest (lowest OID).
+	 * The first candidate is the one to keep.
+	 */
 	rawSql := `
        SELECT
           schema_name,
-          PG_SIZE_PRETTY(SUM(PG_RELATION_SIZE(idx))::BIGINT) AS size_human,
-          SUM(PG_RELATION_SIZE(idx))::BIGINT AS size_bytes,
-          (ARRAY_AGG(idx::REGCLASS::TEXT))[1] AS index1,
-          (ARRAY_AGG(idx::REGCLASS::TEXT))[2] AS index2,
-          (ARRAY_AGG(idx::REGCLASS::TEXT))[3] AS index3,
-          (ARRAY_AGG(idx::REGCLASS::TEXT))[4] AS index4
+          table_name,
+          PG_SIZE_PRETTY(SUM(size_bytes)::BIGINT) AS size_human,
+          SUM(size_bytes)::BIGINT AS size_bytes,
+          ARRAY_AGG(index_name ORDER BY keep_priority, idx) AS index_names,
+          ARRAY_AGG(PG_SIZE_PRETTY(size_bytes) ORDER BY keep_priority, idx) AS index_sizes_human,
+          ARRAY_AGG(size_bytes ORDER BY keep_priority, idx) AS index_sizes_bytes,
+          ARRAY_AGG(scans ORDER BY keep_priority, idx) AS index_scans,
+          ARRAY_AGG(constraint_role ORDER BY keep_priority, idx) AS index_roles
        FROM (
           SELECT
              n.nspname AS schema_name,
-             indexrelid AS idx,
+             t.relname AS table_name,
+             i.indexrelid AS idx,
+             i.indexrelid::REGCLASS::TEXT AS index_name,
+             PG_RELATION_SIZE(i.indexrelid) AS size_bytes,
+             COALESCE(s.idx_scan, 0) AS scans,
+             ARRAY_TO_STRING(ARRAY_REMOVE(ARRAY[
+                CASE WHEN i.indisprimary THEN 'PK' END,
+                CASE WHEN con.contype = 'u' THEN 'UNIQUE' END,
+                CASE WHEN con.contype = 'x' THEN 'EXCLUSION' END,
+                CASE WHEN i.indisunique AND con.contype IS NULL THEN 'UNIQUE INDEX' END,
+                CASE WHEN i.indisreplident THEN 'REPLICA IDENTITY' END,
+                CASE WHEN fk.is_referenced THEN 'FK TARGET' END
+             ], NULL), ', ') AS constraint_role,
+             CASE
+                WHEN i.indisprimary THEN 0
+                WHEN con.contype IN ('u', 'x') THEN 1
+                WHEN i.indisunique THEN 2
+                WHEN i.indisreplident THEN 3
+                WHEN fk.is_referenced THEN 4
+                ELSE 5
+             END AS keep_priority,
+             -- Unique and non-unique indexes are never duplicates of each other
              (
                 indrelid::TEXT || E'\n' ||
+                indisunique::TEXT || E'\n' ||
                 indclass::TEXT || E'\n' ||
                 indkey::TEXT || E'\n' ||
                 indoption::TEXT || E'\n' ||
@@ -92,14 +133,34 @@ func run(opts *Options) {
           FROM pg_index AS i
           JOIN pg_class AS c
             ON c.oid = i.indexrelid
+          JOIN pg_class AS t
+            ON t.oid = i.indrelid
           JOIN pg_namespace AS n
             ON n.oid = c.relnamespace
+          LEFT JOIN pg_stat_all_indexes AS s
+            ON s.indexrelid = i.indexrelid
+          -- Constraint owned by the index itself (FKs also store conindid, but of the referenced table)
+          LEFT JOIN pg_constraint AS con
+            ON con.conindid = i.indexrelid
+           AND con.conrelid = i.indrelid
+           AND con.contype IN ('p', 'u', 'x')
+          -- FKs of other tables pointing at this index
+          CROSS JOIN LATERAL (
+             SELECT EXISTS (
+                SELECT 1
+                FROM pg_constraint AS f
+                WHERE f.contype = 'f'
+                  AND f.conindid = i.indexrelid
+             ) AS is_referenced
+          ) AS fk
           WHERE 
             ($1 = '*' OR n.nspname = $1)
             AND n.nspname NOT IN ('pg_catalog', 'information_schema')
             AND n.nspname NOT LIKE 'pg_toast%'
+            AND NOT (n.nspname = ANY($2::TEXT[]))
+            AND NOT (t.relname = ANY($3::TEXT[]) OR n.nspname || '.' || t.relname = ANY($3::TEXT[]))
        ) sub
-       GROUP BY schema_name, sub.key 
+       GROUP BY schema_name, table_name, sub.key 
        HAVING COUNT(*) > 1
        ORDER BY size_bytes DESC;
     `
@@ -124,7 +185,7 @@ func run(opts *Options) {
 		}
 	}(conn, ctx)
 
-	rows, err := conn.Query(ctx, sqlQuery, opts.Schema)
+	rows, err := conn.Query(ctx, sqlQuery, opts.Schema, opts.Exclude.Schemas, opts.Exclude.Tables)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Query failed: %v\n", err)
 		os.Exit(1)
@@ -135,37 +196,42 @@ func run(opts *Options) {
 
 	for rows.Next() {
 		var r duplicateRow
-		// Используем указатели, так как 2, 3 и 4 индексы могут быть NULL
-		var idx1, idx2, idx3, idx4 *string
+		var names, sizesHuman, roles []string
+		var sizesBytes, scans []int64
 
 		err := rows.Scan(
 			&r.Schema,
+			&r.Table,
 			&r.SizeHuman,
 			&r.SizeBytes,
-			&idx1,
-			&idx2,
-			&idx3,
-			&idx4,
+			&names,
+			&sizesHuman,
+			&sizesBytes,
+			&scans,
+			&roles,
 		)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Row scan failed: %v\n", err)
 			os.Exit(1)
 		}
 
-		// Logic: keep the first found index, suggest dropping the rest
-		if idx1 != nil {
-			r.KeepIndex = *idx1
+		r.Candidates = make([]duplicateCandidate, 0, len(names))
+		for i := range names {
+			r.Candidates = append(r.Candidates, duplicateCandidate{
+				Index:          names[i],
+				SizeHuman:      sizesHuman[i],
+				SizeBytes:      sizesBytes[i],
+				Scans:          scans[i],
+				ConstraintRole: roles[i],
+			})
 		}
 
+		// Logic: candidates are already ordered by priority, keep the first one and suggest dropping the rest
+		r.KeepIndex = r.Candidates[0].Index
+
 		r.DropIndexes = []string{}
-		if idx2 != nil {
-			r.DropIndexes = append(r.DropIndexes, *idx2)
-		}
-		if idx3 != nil {
-			r.DropIndexes = append(r.DropIndexes, *idx3)
-		}
-		if idx4 != nil {
-			r.DropIndexes = append(r.DropIndexes, *idx4)
+		for _, c := range r.Candidates[1:] {
+			r.DropIndexes = append(r.DropIndexes, c.Index)
 		}
 
 		results = append(results, r)
@@ -196,15 +262,20 @@ func run(opts *Options) {
 		}
 
 		table := tablewriter.NewWriter(os.Stdout)
-		table.Header([]string{"Schema", "Wasted Size", "Keep Index", "Drop Duplicate(s)"})
+		table.Header([]string{"Schema", "Table", "Total Size", "Keep Index", "Drop Duplicate(s)"})
 
 		for _, row := range results {
-			dropList := strings.Join(row.DropIndexes, ", ")
+			dropList := make([]string, 0, len(row.Candidates)-1)
+			for _, c := range row.Candidates[1:] {
+				dropList = append(dropList, formatCandidate(c))
+			}
+
 			err := table.Append([]string{
 				row.Schema,
+				row.Table,
 				row.SizeHuman,
-				row.KeepIndex,
-				dropList,
+				formatCandidate(row.Candidates[0]),
+				strings.Join(dropList, "\n"),
 			})
 			if err != nil {
 				fmt.Fprintf(os.Stderr, "Error appending table row: %v\n", err)
@@ -215,9 +286,19 @@ func run(opts *Options) {
 		}
 
 		fmt.Println(strings.Repeat("-", 80))
-		fmt.Println("* Warning: The 'Keep' index is simply the first one found.")
-		fmt.Println("* Check if one name follows your naming convention better than the others before dropping.")
+		fmt.Println("* 'Keep' is chosen by role: PK > UNIQUE/EXCLUSION > UNIQUE INDEX > REPLICA IDENTITY > FK TARGET > oldest index.")
+		fmt.Println("* Duplicates backing a constraint must be removed via ALTER TABLE ... DROP CONSTRAINT, not DROP INDEX.")
+		fmt.Println("* FK TARGET duplicates are referenced by foreign keys: re-point or recreate those FKs before dropping.")
+	}
+}
+
+// formatCandidate renders an index with its size, scans count and constraint role for the table output.
+func formatCandidate(c duplicateCandidate) string {
+	s := fmt.Sprintf("%s (%s, %d scans)", c.Index, c.SizeHuman, c.Scans)
+	if c.ConstraintRole != "" {
+		s += " [" + c.ConstraintRole + "]"
 	}
+	return s
 }
 
 func printExplanation(sqlQuery string, opts *Options) {
@@ -234,10 +315,13 @@ func printExplanation(sqlQuery string, opts *Options) {
 	fmt.Println("• Duplicate indexes are pure overhead.")
 	fmt.Println("• They double the maintenance cost for INSERT/UPDATE/DELETE.")
 	fmt.Println("• They take up disk space and RAM (buffer cache) for no benefit.")
+	fmt.Println("• Keep: The index backing a PK, UNIQUE constraint, UNIQUE index or REPLICA IDENTITY is preferred,")
+	fmt.Println("  then one referenced by a foreign key, otherwise the oldest one (lowest OID).")
+	fmt.Println("• A UNIQUE index and a plain index on the same columns are not duplicates: the uniqueness would be lost.")
 	fmt.Println("• Action: You should safely DROP the duplicates and keep one.")
 	fmt.Println("")
 
 	fmt.Println("💻 SQL QUERY")
 	fmt.Println("------------")
-	util.PrintRunnableSQL(sqlQuery, []interface{}{opts.Schema})
+	util.PrintRunnableSQL(sqlQuery, []interface{}{opts.Schema, opts.Exclude.Schemas, opts.Exclude.Tables})
 }
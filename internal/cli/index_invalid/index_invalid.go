@@ -18,6 +18,7 @@ import (
 type Options struct {
 	DbName  string
 	Schema  string
+	Exclude util.Exclusion
 	Explain bool
 	Output  util.OutputFormat
 }
@@ -47,6 +48,7 @@ func NewCommand() *cobra.Command {
 
 	flags := command.Flags()
 	flags.StringVar(&opts.Schema, "schema", opts.Schema, "Schema name (use '*' for all user schemas)")
+	util.AddExclusionFlags(flags, &opts.Exclude)
 	flags.BoolVar(&opts.Explain, "explain", false, "Print the SQL query and explain the logic/interpretation")
 
 	flags.Var(&opts.Output, "output", "Output format (table, json)")
@@ -87,6 +89,8 @@ func run(opts *Options) {
           ($1 = '*' OR n.nspname = $1)
           AND n.nspname NOT IN ('pg_catalog', 'information_schema')
           AND n.nspname NOT LIKE 'pg_toast%'
+          AND NOT (n.nspname = ANY($2::TEXT[]))
+          AND NOT (t.relname = ANY($3::TEXT[]) OR n.nspname || '.' || t.relname = ANY($3::TEXT[]))
        ORDER BY n.nspname, t.relname, i.relname;
     `
 
@@ -110,7 +114,7 @@ func run(opts *Options) {
 		}
 	}(conn, ctx)
 
-	rows, err := conn.Query(ctx, sqlQuery, opts.Schema)
+	rows, err := conn.Query(ctx, sqlQuery, opts.Schema, opts.Exclude.Schemas, opts.Exclude.Tables)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Query failed: %v\n", err)
 		os.Exit(1)
@@ -223,5 +227,5 @@ func printExplanation(sqlQuery string, opts *Options) {
 
 	fmt.Println("💻 SQL QUERY")
 	fmt.Println("------------")
-	util.PrintRunnableSQL(sqlQuery, []interface{}{opts.Schema})
+	util.PrintRunnableSQL(sqlQuery, []interface{}{opts.Schema, opts.Exclude.Schemas, opts.Exclude.Tables})
 }
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"regexp"
 	"strings"
 
 	"github.com/pg-ok/pgok/internal/db"
@@ -18,9 +19,13 @@ import (
 type Options struct {
 	DbName  string
 	Schema  string
+	Exclude util.Exclusion
 	RowsMin int64
 	Explain bool
 	Output  util.OutputFormat
+
+	WithQueries  bool
+	QueriesLimit int64
 }
 
 func NewCommand() *cobra.Command {
@@ -30,6 +35,8 @@ func NewCommand() *cobra.Command {
 
 		RowsMin: 1000,
 
+		QueriesLimit: 3,
+
 		Output: util.OutputFormatTable,
 	}
 
@@ -50,7 +57,10 @@ func NewCommand() *cobra.Command {
 
 	flags := command.Flags()
 	flags.StringVar(&opts.Schema, "schema", opts.Schema, "Schema name (use '*' for all user schemas)")
+	util.AddExclusionFlags(flags, &opts.Exclude)
 	flags.Int64Var(&opts.RowsMin, "rows-min", opts.RowsMin, "Minimum table rows to calculate ratio (ignore small tables)")
+	flags.BoolVar(&opts.WithQueries, "with-queries", false, "Show top pg_stat_statements queries referencing each table")
+	flags.Int64Var(&opts.QueriesLimit, "queries-limit", opts.QueriesLimit, "Maximum number of queries per table (with --with-queries)")
 	flags.BoolVar(&opts.Explain, "explain", false, "Print the SQL query and explain the logic/interpretation")
 
 	flags.Var(&opts.Output, "output", "Output format (table, json)")
@@ -62,13 +72,85 @@ func NewCommand() *cobra.Command {
 }
 
 type missingIndexRow struct {
-	Schema             string   `json:"schema"`
-	Table              string   `json:"table"`
-	SequentialScans    int64    `json:"sequential_scans"`
-	IndexScans         int64    `json:"index_scans"`
-	RowsReadSequential int64    `json:"rows_read_sequential"`
-	TableRows          int64    `json:"table_rows"`
-	Ratio              *float64 `json:"ratio"` // Pointer to handle NULL (Inf)
+	Schema             string       `json:"schema"`
+	Table              string       `json:"table"`
+	SequentialScans    int64        `json:"sequential_scans"`
+	IndexScans         int64        `json:"index_scans"`
+	RowsReadSequential int64        `json:"rows_read_sequential"`
+	TableRows          int64        `json:"table_rows"`
+	Ratio              *float64     `json:"ratio"` // Pointer to handle NULL (Inf)
+	Queries            []tableQuery `json:"queries,omitempty"`
+}
+
+type tableQuery struct {
+	Query          string  `json:"query"`
+	Calls          int64   `json:"calls"`
+	TotalTimeMs    float64 `json:"total_time_ms"`
+	SharedBlksRead int64   `json:"shared_blks_read"`
+}
+
+// buildQueriesSql renders the statements lookup for the detected pg_stat_statements layout.
+// Tables are matched by name in the normalized query text, so the result is a hint, not a proof.
+func buildQueriesSql(statements *db.StatStatements) string {
+	rawSql := `
+       SELECT
+          query,
+          calls,
+          ROUND(%[2]s::NUMERIC, 2)::FLOAT AS total_time_ms,
+          shared_blks_read
+       FROM %[1]s
+       WHERE
+          dbid = (SELECT oid FROM pg_database WHERE datname = current_database())
+          AND query ~* $1
+       ORDER BY shared_blks_read DESC, %[2]s DESC
+       LIMIT $2;
+    `
+
+	return util.TrimLeftSpaces(fmt.Sprintf(rawSql, statements.View, statements.TotalTimeColumn))
+}
+
+// tablePattern matches the table name, optionally schema-qualified and quoted, as a whole word.
+func tablePattern(schema, table string) string {
+	return fmt.Sprintf(
+		`\m("?%s"?\.)?"?%s"?\M`,
+		regexp.QuoteMeta(schema),
+		regexp.QuoteMeta(table),
+	)
+}
+
+func queryTableQueries(ctx context.Context, conn *pgx.Conn, sqlQuery string, row missingIndexRow, opts *Options) []tableQuery {
+	rows, err := conn.Query(ctx, sqlQuery, tablePattern(row.Schema, row.Table), opts.QueriesLimit)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Query failed: %v\n", err)
+		os.Exit(1)
+	}
+	defer rows.Close()
+
+	var results []tableQuery
+
+	for rows.Next() {
+		var q tableQuery
+
+		err := rows.Scan(
+			&q.Query,
+			&q.Calls,
+			&q.TotalTimeMs,
+			&q.SharedBlksRead,
+		)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Row scan failed: %v\n", err)
+			os.Exit(1)
+		}
+
+		results = append(results, q)
+	}
+
+	if rows.Err() != nil {
+		fmt.Fprintf(os.Stderr, "Rows iteration failed: %v\n", rows.Err())
+		os.Exit(1)
+	}
+
+	return results
 }
 
 func run(opts *Options) {
@@ -91,13 +173,15 @@ func run(opts *Options) {
           ($1 = '*' OR schemaname = $1)
           AND seq_scan > 0
           AND n_live_tup >= $2
+          AND NOT (schemaname = ANY($3::TEXT[]))
+          AND NOT (relname = ANY($4::TEXT[]) OR schemaname || '.' || relname = ANY($4::TEXT[]))
        ORDER BY seq_tup_read DESC;
     `
 
 	sqlQuery := util.TrimLeftSpaces(rawSql)
 
 	if opts.Explain {
-		printExplanation(sqlQuery, opts)
+		printExplanation(sqlQuery, buildQueriesSql(db.DefaultStatStatements), opts)
 		return
 	}
 
@@ -114,7 +198,7 @@ func run(opts *Options) {
 		}
 	}(conn, ctx)
 
-	rows, err := conn.Query(ctx, sqlQuery, opts.Schema, opts.RowsMin)
+	rows, err := conn.Query(ctx, sqlQuery, opts.Schema, opts.RowsMin, opts.Exclude.Schemas, opts.Exclude.Tables)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Query failed: %v\n", err)
 		os.Exit(1)
@@ -148,6 +232,21 @@ func run(opts *Options) {
 		os.Exit(1)
 	}
 
+	withQueries := false
+	if opts.WithQueries && len(results) > 0 {
+		statements, err := db.GetStatStatements(ctx, conn)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+			fmt.Fprintln(os.Stderr, "Warning: continuing without --with-queries")
+		} else {
+			withQueries = true
+			queriesSql := buildQueriesSql(statements)
+			for i := range results {
+				results[i].Queries = queryTableQueries(ctx, conn, queriesSql, results[i], opts)
+			}
+		}
+	}
+
 	switch opts.Output {
 	case util.OutputFormatJson:
 		jsonData, _ := json.MarshalIndent(results, "", "  ")
@@ -199,13 +298,49 @@ func run(opts *Options) {
 			fmt.Fprintf(os.Stderr, "Error rendering table: %v\n", err)
 		}
 
+		if withQueries {
+			fmt.Println("")
+			fmt.Println("Top queries referencing the tables above (pg_stat_statements)")
+
+			queriesTable := tablewriter.NewWriter(os.Stdout)
+			queriesTable.Header([]string{"Schema", "Table", "Query", "Calls", "Total ms", "Shared Reads"})
+
+			for _, row := range results {
+				for _, q := range row.Queries {
+					// Normalize whitespace and truncate the query for display purposes only
+					queryDisplay := strings.Join(strings.Fields(q.Query), " ")
+					if len(queryDisplay) > 60 {
+						queryDisplay = queryDisplay[0:57] + "..."
+					}
+
+					err := queriesTable.Append([]string{
+						row.Schema,
+						row.Table,
+						queryDisplay,
+						fmt.Sprintf("%d", q.Calls),
+						fmt.Sprintf("%.0f", q.TotalTimeMs),
+						fmt.Sprintf("%d", q.SharedBlksRead),
+					})
+					if err != nil {
+						fmt.Fprintf(os.Stderr, "Error appending table row: %v\n", err)
+					}
+				}
+			}
+			if err := queriesTable.Render(); err != nil {
+				fmt.Fprintf(os.Stderr, "Error rendering table: %v\n", err)
+			}
+		}
+
 		fmt.Println(strings.Repeat("-", 115))
 		fmt.Printf("* Hidden tables with < %d rows (Seq Scan is usually fine there).\n", opts.RowsMin)
 		fmt.Println("* Ratio = Rows Read Seq / Index Scans. High ratio means we read MANY rows for every index scan (or lack thereof).")
+		if withQueries {
+			fmt.Println("* Queries are matched by table name in the query text. Use --output=json to get the full query text.")
+		}
 	}
 }
 
-func printExplanation(sqlQuery string, opts *Options) {
+func printExplanation(sqlQuery string, queriesSqlQuery string, opts *Options) {
 	fmt.Println("📖 EXPLANATION")
 	fmt.Println("-------------")
 	fmt.Println("When PostgreSQL cannot find a suitable index for a query, it performs a Sequential Scan")
@@ -217,9 +352,16 @@ func printExplanation(sqlQuery string, opts *Options) {
 	fmt.Println("• Ratio: Number of rows read by sequential scans divided by the number of index scans.")
 	fmt.Println("• High Ratio (> 1000): Means we are reading MILLIONS of rows via Seq Scan compared to Index Scans.")
 	fmt.Println("• Action: Look at slow queries filtering on this table and add indexes on the columns used in WHERE.")
+	fmt.Println("• --with-queries: Lists pg_stat_statements queries mentioning the table, ordered by shared block reads.")
+	fmt.Println("  These are the best candidates to check with EXPLAIN (ANALYZE, BUFFERS).")
 	fmt.Println("")
 
 	fmt.Println("💻 SQL QUERY")
 	fmt.Println("------------")
-	util.PrintRunnableSQL(sqlQuery, []interface{}{opts.Schema, opts.RowsMin})
+	util.PrintRunnableSQL(sqlQuery, []interface{}{opts.Schema, opts.RowsMin, opts.Exclude.Schemas, opts.Exclude.Tables})
+
+	if opts.WithQueries {
+		fmt.Println("")
+		util.PrintRunnableSQL(queriesSqlQuery, []interface{}{tablePattern("<schema>", "<table>"), opts.QueriesLimit})
+	}
 }
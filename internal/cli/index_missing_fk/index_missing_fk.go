@@ -18,6 +18,7 @@ import (
 type Options struct {
 	DbName  string
 	Schema  string
+	Exclude util.Exclusion
 	Explain bool
 	Output  util.OutputFormat
 }
@@ -51,6 +52,7 @@ and slow down DELETE/UPDATE operations on the parent table.`,
 
 	flags := command.Flags()
 	flags.StringVar(&opts.Schema, "schema", opts.Schema, "Schema name (use '*' for all user schemas)")
+	util.AddExclusionFlags(flags, &opts.Exclude)
 	flags.BoolVar(&opts.Explain, "explain", false, "Print the SQL query and explain the logic/interpretation")
 
 	flags.Var(&opts.Output, "output", "Output format (table, json)")
@@ -62,10 +64,17 @@ and slow down DELETE/UPDATE operations on the parent table.`,
 }
 
 type fkMissingRow struct {
-	Schema     string `json:"schema"`
-	Table      string `json:"table"`
-	ForeignKey string `json:"foreign_key"`
-	Definition string `json:"definition"`
+	Schema             string   `json:"schema"`
+	Table              string   `json:"table"`
+	ForeignKey         string   `json:"foreign_key"`
+	Definition         string   `json:"definition"`
+	ReferencedTable    string   `json:"referenced_table"`
+	TableRowsEstimate  int64    `json:"table_rows_estimate"`
+	TableSizeHuman     string   `json:"table_size_human"`
+	TableSizeBytes     int64    `json:"table_size_bytes"`
+	ParentWrites       int64    `json:"parent_writes"`
+	ParentWritesPerDay float64  `json:"parent_writes_per_day"`
+	PartialIndexes     []string `json:"partial_indexes"`
 }
 
 func run(opts *Options) {
@@ -73,33 +82,67 @@ func run(opts *Options) {
 
 	/*
 	 * This SQL query searches for Foreign Keys that lack an index
-	 * where the FK columns match the index's leading columns.
+	 * where the FK columns match the index's leading columns (in any order).
+	 * Partial indexes are not counted as covering, but are reported for reference.
 	 */
 	rawSql := `
        SELECT
           n.nspname AS schema_name,
           cl.relname AS table_name,
           c.conname AS foreign_key,
-          pg_get_constraintdef(c.oid) AS definition
+          pg_get_constraintdef(c.oid) AS definition,
+          c.confrelid::REGCLASS::TEXT AS referenced_table,
+          GREATEST(cl.reltuples, 0)::BIGINT AS table_rows_estimate,
+          pg_size_pretty(pg_total_relation_size(c.conrelid)) AS table_size_human,
+          pg_total_relation_size(c.conrelid) AS table_size_bytes,
+          COALESCE(ps.n_tup_upd + ps.n_tup_del, 0) AS parent_writes,
+          ROUND(
+             COALESCE(ps.n_tup_upd + ps.n_tup_del, 0)::NUMERIC
+                / GREATEST(EXTRACT(EPOCH FROM now() - COALESCE(d.stats_reset, pg_postmaster_start_time())), 1)
+                * 86400,
+             2
+          )::FLOAT AS parent_writes_per_day,
+          COALESCE(cov.partial_indexes, '{}') AS partial_indexes
        FROM pg_constraint AS c
        JOIN pg_namespace AS n ON n.oid = c.connamespace
        JOIN pg_class AS cl ON cl.oid = c.conrelid
+       -- UPDATEs/DELETEs on the parent are what trigger lookups in the child table
+       LEFT JOIN pg_stat_all_tables AS ps ON ps.relid = c.confrelid
+       CROSS JOIN (
+          SELECT stats_reset
+          FROM pg_stat_database
+          WHERE datname = current_database()
+       ) AS d
+       -- FK columns as a sorted set, so the index prefix may list them in any order
+       CROSS JOIN LATERAL (
+          SELECT ARRAY_AGG(k ORDER BY k) AS cols
+          FROM UNNEST(c.conkey) AS k
+       ) AS fk_cols
+       LEFT JOIN LATERAL (
+          SELECT
+             BOOL_OR(i.indpred IS NULL) AS has_full_index,
+             ARRAY_AGG(i.indexrelid::REGCLASS::TEXT) FILTER (WHERE i.indpred IS NOT NULL) AS partial_indexes
+          FROM pg_index AS i
+          WHERE i.indrelid = c.conrelid
+          AND i.indisvalid
+          -- INCLUDE columns cannot be used for lookups, only key columns count
+          AND array_length(c.conkey, 1) <= i.indnkeyatts
+          -- The first N index columns (N = number of FK columns) must be exactly the FK columns.
+          -- Expression columns are stored as 0 in indkey and therefore never match.
+          AND (
+             SELECT ARRAY_AGG(u.k ORDER BY u.k)
+             FROM UNNEST(i.indkey::int2[]) WITH ORDINALITY AS u(k, pos)
+             WHERE u.pos <= array_length(c.conkey, 1)
+          ) = fk_cols.cols
+       ) AS cov ON true
        WHERE c.contype = 'f' -- Only Foreign Keys
        AND ($1 = '*' OR n.nspname = $1)
        AND n.nspname NOT IN ('pg_catalog', 'information_schema')
        AND n.nspname NOT LIKE 'pg_toast%'
-       AND NOT EXISTS (
-          SELECT 1
-          FROM pg_index AS i
-          WHERE i.indrelid = c.conrelid
-          AND i.indisvalid
-          -- Check if the FK columns match the *prefix* of the index columns.
-          -- conkey: array of FK columns
-          -- indkey: array of index columns (cast to int2[] for comparison)
-          -- Slicing [1: ...] takes a prefix of the index array with the same length as the FK.
-          AND (i.indkey::int2[])[1:array_length(c.conkey, 1)] = c.conkey::int2[]
-       )
-       ORDER BY schema_name, table_name, foreign_key;
+       AND NOT (n.nspname = ANY($2::TEXT[]))
+       AND NOT (cl.relname = ANY($3::TEXT[]) OR n.nspname || '.' || cl.relname = ANY($3::TEXT[]))
+       AND COALESCE(cov.has_full_index, false) = false
+       ORDER BY parent_writes_per_day DESC, table_size_bytes DESC, schema_name, table_name, foreign_key;
     `
 
 	sqlQuery := util.TrimLeftSpaces(rawSql)
@@ -122,7 +165,7 @@ func run(opts *Options) {
 		}
 	}(conn, ctx)
 
-	rows, err := conn.Query(ctx, sqlQuery, opts.Schema)
+	rows, err := conn.Query(ctx, sqlQuery, opts.Schema, opts.Exclude.Schemas, opts.Exclude.Tables)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Query failed: %v\n", err)
 		os.Exit(1)
@@ -139,6 +182,13 @@ func run(opts *Options) {
 			&r.Table,
 			&r.ForeignKey,
 			&r.Definition,
+			&r.ReferencedTable,
+			&r.TableRowsEstimate,
+			&r.TableSizeHuman,
+			&r.TableSizeBytes,
+			&r.ParentWrites,
+			&r.ParentWritesPerDay,
+			&r.PartialIndexes,
 		)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Row scan failed: %v\n", err)
@@ -174,7 +224,7 @@ func run(opts *Options) {
 		}
 
 		table := tablewriter.NewWriter(os.Stdout)
-		table.Header([]string{"Schema", "Table", "Foreign Key", "Definition"})
+		table.Header([]string{"Schema", "Table", "Foreign Key", "Definition", "Table Size (Rows)", "Parent Writes/Day"})
 
 		for _, row := range results {
 			// Truncate definition for display purposes only (in Raw mode)
@@ -183,11 +233,18 @@ func run(opts *Options) {
 				definitionDisplay = row.Definition[0:37] + "..."
 			}
 
+			foreignKeyDisplay := row.ForeignKey
+			if len(row.PartialIndexes) > 0 {
+				foreignKeyDisplay += " [P]"
+			}
+
 			err := table.Append([]string{
 				row.Schema,
 				row.Table,
-				row.ForeignKey,
+				foreignKeyDisplay,
 				definitionDisplay,
+				fmt.Sprintf("%s (~%d)", row.TableSizeHuman, row.TableRowsEstimate),
+				fmt.Sprintf("%.0f", row.ParentWritesPerDay),
 			})
 			if err != nil {
 				fmt.Fprintf(os.Stderr, "Error appending table row: %v\n", err)
@@ -199,6 +256,8 @@ func run(opts *Options) {
 
 		fmt.Println(strings.Repeat("-", 80))
 		fmt.Println("* Tip: Indexes on FKs are crucial for CASCADE DELETE performance and avoiding locking issues.")
+		fmt.Println("* Sorted by parent UPDATE+DELETE rate: FKs on the top matter the most.")
+		fmt.Println("* [P] = Only a partial index covers the FK columns. It is not used for integrity checks of all rows.")
 	}
 }
 
@@ -216,10 +275,14 @@ func printExplanation(sqlQuery string, opts *Options) {
 	fmt.Println("  the child table to ensure referential integrity. Without an index, this often")
 	fmt.Println("  requires locking the ENTIRE child table, blocking other transactions.")
 	fmt.Println("• Performance: Deletes on parent become slow (Sequential Scan on child).")
+	fmt.Println("• Coverage: An index covers the FK if its leading columns are the FK columns in any order.")
+	fmt.Println("  Partial indexes (WHERE ...) are NOT counted as covering, they are listed for reference only.")
+	fmt.Println("• Priority: Parent Writes/Day is the UPDATE+DELETE rate on the parent table since statistics reset")
+	fmt.Println("  (or server start). Large child tables with a busy parent should be fixed first.")
 	fmt.Println("• Action: Create an index on the Foreign Key column(s) in the child table.")
 	fmt.Println("")
 
 	fmt.Println("💻 SQL QUERY")
 	fmt.Println("------------")
-	util.PrintRunnableSQL(sqlQuery, []interface{}{opts.Schema})
+	util.PrintRunnableSQL(sqlQuery, []interface{}{opts.Schema, opts.Exclude.Schemas, opts.Exclude.Tables})
 }
@@ -17,6 +17,7 @@ import (
 type Options struct {
 	DbName  string
 	Schema  string
+	Exclude util.Exclusion
 	SizeMin int64
 	Explain bool
 	Output  util.OutputFormat
@@ -49,6 +50,7 @@ func NewCommand() *cobra.Command {
 
 	flags := command.Flags()
 	flags.StringVar(&opts.Schema, "schema", opts.Schema, "Schema name (use '*' for all user schemas)")
+	util.AddExclusionFlags(flags, &opts.Exclude)
 	flags.Int64Var(&opts.SizeMin, "size-min", opts.SizeMin, "Minimum index size in bytes (exclude smaller indexes)")
 	flags.BoolVar(&opts.Explain, "explain", false, "Print the SQL query and explain the logic/interpretation")
 
@@ -89,6 +91,8 @@ func run(opts *Options) {
           ($1 = '*' OR n.nspname = $1)
           AND n.nspname NOT IN ('pg_catalog', 'information_schema')
           AND n.nspname NOT LIKE 'pg_toast%'
+          AND NOT (n.nspname = ANY($3::TEXT[]))
+          AND NOT (t.relname = ANY($4::TEXT[]) OR n.nspname || '.' || t.relname = ANY($4::TEXT[]))
           AND ix.indisprimary = false -- Excluding primary key
           AND pg_relation_size(i.oid) >= $2
        ORDER BY index_size_bytes DESC;
@@ -114,7 +118,7 @@ func run(opts *Options) {
 		}
 	}(conn, ctx)
 
-	rows, err := conn.Query(ctx, sqlQuery, opts.Schema, opts.SizeMin)
+	rows, err := conn.Query(ctx, sqlQuery, opts.Schema, opts.SizeMin, opts.Exclude.Schemas, opts.Exclude.Tables)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Query failed: %v\n", err)
 		os.Exit(1)
@@ -196,5 +200,5 @@ func printExplanation(sqlQuery string, opts *Options) {
 
 	fmt.Println("💻 SQL QUERY")
 	fmt.Println("------------")
-	util.PrintRunnableSQL(sqlQuery, []interface{}{opts.Schema, opts.SizeMin})
+	util.PrintRunnableSQL(sqlQuery, []interface{}{opts.Schema, opts.SizeMin, opts.Exclude.Schemas, opts.Exclude.Tables})
 }
@@ -18,6 +18,7 @@ import (
 type Options struct {
 	DbName  string
 	Schema  string
+	Exclude util.Exclusion
 	ScanMax int64
 	Explain bool
 	Output  util.OutputFormat
@@ -50,6 +51,7 @@ func NewCommand() *cobra.Command {
 
 	flags := command.Flags()
 	flags.StringVar(&opts.Schema, "schema", opts.Schema, "Schema name (use '*' for all user schemas)")
+	util.AddExclusionFlags(flags, &opts.Exclude)
 	flags.Int64Var(&opts.ScanMax, "scan-count-max", opts.ScanMax, "Maximum scans count")
 	flags.BoolVar(&opts.Explain, "explain", false, "Print the SQL query and explain the logic/interpretation")
 
@@ -85,6 +87,8 @@ func run(opts *Options) {
           -- pg_stat_user_indexes already excludes system schemas, but we keep this for consistency
           AND s.schemaname NOT IN ('pg_catalog', 'information_schema')
           AND s.schemaname NOT LIKE 'pg_toast%'
+          AND NOT (s.schemaname = ANY($3::TEXT[]))
+          AND NOT (s.relname = ANY($4::TEXT[]) OR s.schemaname || '.' || s.relname = ANY($4::TEXT[]))
           AND s.idx_scan <= $2
           AND i.indisprimary = false
        ORDER BY s.schemaname, s.relname, s.idx_scan;
@@ -110,7 +114,7 @@ func run(opts *Options) {
 		}
 	}(conn, ctx)
 
-	rows, err := conn.Query(ctx, sqlQuery, opts.Schema, opts.ScanMax)
+	rows, err := conn.Query(ctx, sqlQuery, opts.Schema, opts.ScanMax, opts.Exclude.Schemas, opts.Exclude.Tables)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Query failed: %v\n", err)
 		os.Exit(1)
@@ -203,5 +207,5 @@ func printExplanation(sqlQuery string, opts *Options) {
 
 	fmt.Println("💻 SQL QUERY")
 	fmt.Println("------------")
-	util.PrintRunnableSQL(sqlQuery, []interface{}{opts.Schema, opts.ScanMax})
+	util.PrintRunnableSQL(sqlQuery, []interface{}{opts.Schema, opts.ScanMax, opts.Exclude.Schemas, opts.Exclude.Tables})
 }
@@ -0,0 +1,216 @@
+package partition_default_missing
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/pg-ok/pgok/internal/db"
+	"github.com/pg-ok/pgok/internal/util"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+type Options struct {
+	DbName  string
+	Schema  string
+	Exclude util.Exclusion
+	Explain bool
+	Output  util.OutputFormat
+}
+
+func NewCommand() *cobra.Command {
+	opts := &Options{
+		// Default to scanning all schemas
+		Schema: "*",
+
+		Output: util.OutputFormatTable,
+	}
+
+	command := &cobra.Command{
+		GroupID: "partition",
+
+		Use: "partition:default-missing [db_name]",
+
+		Short: "Find RANGE/LIST partitioned tables without a DEFAULT partition",
+
+		Args: cobra.ExactArgs(1),
+
+		Run: func(cmd *cobra.Command, args []string) {
+			opts.DbName = args[0]
+			run(opts)
+		},
+	}
+
+	flags := command.Flags()
+	flags.StringVar(&opts.Schema, "schema", opts.Schema, "Schema name (use '*' for all user schemas)")
+	util.AddExclusionFlags(flags, &opts.Exclude)
+	flags.BoolVar(&opts.Explain, "explain", false, "Print the SQL query and explain the logic/interpretation")
+
+	flags.Var(&opts.Output, "output", "Output format (table, json)")
+	_ = command.RegisterFlagCompletionFunc("output", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{"table", "json"}, cobra.ShellCompDirectiveDefault
+	})
+
+	return command
+}
+
+type defaultMissingRow struct {
+	Schema       string `json:"schema"`
+	Table        string `json:"table"`
+	Strategy     string `json:"strategy"`
+	PartitionKey string `json:"partition_key"`
+	Partitions   int64  `json:"partitions"`
+}
+
+func run(opts *Options) {
+	manager := db.NewDbManager()
+
+	// HASH partitioned tables can't have a DEFAULT partition, so they are skipped
+	rawSql := `
+       SELECT
+          n.nspname AS schema_name,
+          c.relname AS table_name,
+          CASE pt.partstrat
+             WHEN 'r' THEN 'RANGE'
+             WHEN 'l' THEN 'LIST'
+          END AS strategy,
+          pg_get_partkeydef(c.oid) AS partition_key,
+          (
+             SELECT COUNT(*)
+             FROM pg_inherits AS i
+             WHERE i.inhparent = c.oid
+          ) AS partitions
+       FROM pg_partitioned_table AS pt
+       JOIN pg_class AS c
+         ON c.oid = pt.partrelid
+       JOIN pg_namespace AS n
+         ON n.oid = c.relnamespace
+       WHERE 
+          ($1 = '*' OR n.nspname = $1)
+          AND n.nspname NOT IN ('pg_catalog', 'information_schema')
+          AND n.nspname NOT LIKE 'pg_toast%'
+          AND NOT (n.nspname = ANY($2::TEXT[]))
+          AND NOT (c.relname = ANY($3::TEXT[]) OR n.nspname || '.' || c.relname = ANY($3::TEXT[]))
+          AND pt.partstrat IN ('r', 'l')
+          AND pt.partdefid = 0 -- No DEFAULT partition
+       ORDER BY schema_name, table_name;
+    `
+
+	sqlQuery := util.TrimLeftSpaces(rawSql)
+
+	if opts.Explain {
+		printExplanation(sqlQuery, opts)
+		return
+	}
+
+	ctx := context.Background()
+	conn, err := manager.Connect(ctx, opts.DbName)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error connecting to database: %v\n", err)
+		os.Exit(1)
+	}
+	defer func(conn *pgx.Conn, ctx context.Context) {
+		err := conn.Close(ctx)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error closing connection: %v\n", err)
+		}
+	}(conn, ctx)
+
+	rows, err := conn.Query(ctx, sqlQuery, opts.Schema, opts.Exclude.Schemas, opts.Exclude.Tables)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Query failed: %v\n", err)
+		os.Exit(1)
+	}
+	defer rows.Close()
+
+	var results []defaultMissingRow
+
+	for rows.Next() {
+		var r defaultMissingRow
+
+		err := rows.Scan(
+			&r.Schema,
+			&r.Table,
+			&r.Strategy,
+			&r.PartitionKey,
+			&r.Partitions,
+		)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Row scan failed: %v\n", err)
+			os.Exit(1)
+		}
+
+		results = append(results, r)
+	}
+
+	if rows.Err() != nil {
+		fmt.Fprintf(os.Stderr, "Rows iteration failed: %v\n", rows.Err())
+		os.Exit(1)
+	}
+
+	switch opts.Output {
+	case util.OutputFormatJson:
+		jsonData, _ := json.MarshalIndent(results, "", "  ")
+		fmt.Println(string(jsonData))
+
+	default:
+		schemaDisplay := opts.Schema
+		if opts.Schema == "*" {
+			schemaDisplay = "ALL (except system)"
+		}
+
+		fmt.Printf("Searching for partitioned tables without a DEFAULT partition in `%s`\n", opts.DbName)
+		fmt.Printf("Schema: %s\n", schemaDisplay)
+
+		if len(results) == 0 {
+			fmt.Println(strings.Repeat("-", 80))
+			fmt.Println("All RANGE/LIST partitioned tables have a DEFAULT partition.")
+			return
+		}
+
+		table := tablewriter.NewWriter(os.Stdout)
+		table.Header([]string{"Schema", "Table", "Partition Key", "Partitions"})
+
+		for _, row := range results {
+			err := table.Append([]string{
+				row.Schema,
+				row.Table,
+				row.PartitionKey,
+				fmt.Sprintf("%d", row.Partitions),
+			})
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error appending table row: %v\n", err)
+			}
+		}
+		if err := table.Render(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error rendering table: %v\n", err)
+		}
+
+		fmt.Println(strings.Repeat("-", 80))
+		fmt.Println("* INSERTs with a key outside of all partitions fail with 'no partition of relation found for row'.")
+	}
+}
+
+func printExplanation(sqlQuery string, opts *Options) {
+	fmt.Println("📖 EXPLANATION")
+	fmt.Println("-------------")
+	fmt.Println("Rows of a partitioned table are routed to the partition matching the partition key.")
+	fmt.Println("If no partition matches and there is no DEFAULT partition, the INSERT fails.")
+	fmt.Println("")
+
+	fmt.Println("🧠 INTERPRETATION")
+	fmt.Println("-----------------")
+	fmt.Println("• Missing DEFAULT: Any unexpected key (new LIST value, date beyond the last RANGE) breaks writes.")
+	fmt.Println("• Trade-off: A DEFAULT partition must be scanned when attaching new partitions, keep it small.")
+	fmt.Println("• Action: Add a DEFAULT partition or make sure partitions are always created ahead (check partition:gaps).")
+	fmt.Println("")
+
+	fmt.Println("💻 SQL QUERY")
+	fmt.Println("------------")
+	util.PrintRunnableSQL(sqlQuery, []interface{}{opts.Schema, opts.Exclude.Schemas, opts.Exclude.Tables})
+}
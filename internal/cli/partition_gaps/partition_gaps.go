@@ -0,0 +1,427 @@
+package partition_gaps
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pg-ok/pgok/internal/db"
+	"github.com/pg-ok/pgok/internal/util"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+type Options struct {
+	DbName    string
+	Schema    string
+	Exclude   util.Exclusion
+	DaysAhead int
+	Explain   bool
+	Output    util.OutputFormat
+}
+
+func NewCommand() *cobra.Command {
+	opts := &Options{
+		// Default to scanning all schemas
+		Schema: "*",
+
+		DaysAhead: 31,
+
+		Output: util.OutputFormatTable,
+	}
+
+	command := &cobra.Command{
+		GroupID: "partition",
+
+		Use: "partition:gaps [db_name]",
+
+		Short: "Find gaps and missing future partitions in RANGE partitioned tables",
+
+		Long: `Find gaps between partitions and missing future partitions in RANGE partitioned tables.
+Only tables partitioned by a single column are analyzed. Future partitions are checked for
+date/timestamp keys: the last partition must cover at least --days-ahead days from now.`,
+
+		Args: cobra.ExactArgs(1),
+
+		Run: func(cmd *cobra.Command, args []string) {
+			opts.DbName = args[0]
+			run(opts)
+		},
+	}
+
+	flags := command.Flags()
+	flags.StringVar(&opts.Schema, "schema", opts.Schema, "Schema name (use '*' for all user schemas)")
+	util.AddExclusionFlags(flags, &opts.Exclude)
+	flags.IntVar(&opts.DaysAhead, "days-ahead", opts.DaysAhead, "Days from now that must be covered by existing partitions (date/timestamp keys)")
+	flags.BoolVar(&opts.Explain, "explain", false, "Print the SQL query and explain the logic/interpretation")
+
+	flags.Var(&opts.Output, "output", "Output format (table, json)")
+	_ = command.RegisterFlagCompletionFunc("output", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{"table", "json"}, cobra.ShellCompDirectiveDefault
+	})
+
+	return command
+}
+
+type issueType string
+
+const (
+	issueGap    issueType = "GAP"
+	issueFuture issueType = "NO FUTURE PARTITION"
+)
+
+type partitionGapRow struct {
+	Schema       string    `json:"schema"`
+	Table        string    `json:"table"`
+	PartitionKey string    `json:"partition_key"`
+	Issue        issueType `json:"issue"`
+	From         string    `json:"from"`
+	To           string    `json:"to"`
+	After        string    `json:"after_partition"`
+	HasDefault   bool      `json:"has_default"`
+}
+
+// rangePartition is a single partition of a RANGE partitioned table with its bound literals.
+type rangePartition struct {
+	Name string
+	From string
+	To   string
+}
+
+// rangeTable groups the partitions of one RANGE partitioned table.
+type rangeTable struct {
+	Schema       string
+	Table        string
+	PartitionKey string
+	TypeCategory string
+	HasDefault   bool
+	Partitions   []rangePartition
+}
+
+var rangeBoundRe = regexp.MustCompile(`^FOR VALUES FROM \((.*)\) TO \((.*)\)$`)
+
+func run(opts *Options) {
+	manager := db.NewDbManager()
+
+	/*
+	 * Bounds are returned as text (pg_get_expr) and compared in Go,
+	 * using the type category of the partition key column (N = numeric, D = date/time).
+	 */
+	rawSql := `
+       SELECT
+          n.nspname AS schema_name,
+          c.relname AS table_name,
+          pg_get_partkeydef(c.oid) AS partition_key,
+          t.typcategory::TEXT AS type_category,
+          pt.partdefid <> 0 AS has_default,
+          p.relname AS partition_name,
+          pg_get_expr(p.relpartbound, p.oid) AS partition_bound
+       FROM pg_partitioned_table AS pt
+       JOIN pg_class AS c
+         ON c.oid = pt.partrelid
+       JOIN pg_namespace AS n
+         ON n.oid = c.relnamespace
+       -- Expression keys have attnum 0 and are skipped
+       JOIN pg_attribute AS a
+         ON a.attrelid = pt.partrelid
+        AND a.attnum = pt.partattrs[0]
+       JOIN pg_type AS t
+         ON t.oid = a.atttypid
+       JOIN pg_inherits AS i
+         ON i.inhparent = c.oid
+       JOIN pg_class AS p
+         ON p.oid = i.inhrelid
+       WHERE 
+          ($1 = '*' OR n.nspname = $1)
+          AND n.nspname NOT IN ('pg_catalog', 'information_schema')
+          AND n.nspname NOT LIKE 'pg_toast%'
+          AND NOT (n.nspname = ANY($2::TEXT[]))
+          AND NOT (c.relname = ANY($3::TEXT[]) OR n.nspname || '.' || c.relname = ANY($3::TEXT[]))
+          AND pt.partstrat = 'r' -- RANGE partitioning
+          AND pt.partnatts = 1 -- Single column keys
+          AND p.oid <> pt.partdefid -- DEFAULT partition has no bounds
+       ORDER BY schema_name, table_name, partition_name;
+    `
+
+	sqlQuery := util.TrimLeftSpaces(rawSql)
+
+	if opts.Explain {
+		printExplanation(sqlQuery, opts)
+		return
+	}
+
+	ctx := context.Background()
+	conn, err := manager.Connect(ctx, opts.DbName)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error connecting to database: %v\n", err)
+		os.Exit(1)
+	}
+	defer func(conn *pgx.Conn, ctx context.Context) {
+		err := conn.Close(ctx)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error closing connection: %v\n", err)
+		}
+	}(conn, ctx)
+
+	rows, err := conn.Query(ctx, sqlQuery, opts.Schema, opts.Exclude.Schemas, opts.Exclude.Tables)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Query failed: %v\n", err)
+		os.Exit(1)
+	}
+	defer rows.Close()
+
+	var tables []*rangeTable
+
+	for rows.Next() {
+		var t rangeTable
+		var partitionName, partitionBound string
+
+		err := rows.Scan(
+			&t.Schema,
+			&t.Table,
+			&t.PartitionKey,
+			&t.TypeCategory,
+			&t.HasDefault,
+			&partitionName,
+			&partitionBound,
+		)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Row scan failed: %v\n", err)
+			os.Exit(1)
+		}
+
+		match := rangeBoundRe.FindStringSubmatch(partitionBound)
+		if match == nil {
+			fmt.Fprintf(os.Stderr, "Warning: Unexpected partition bound of %s.%s: %s\n", t.Schema, partitionName, partitionBound)
+			continue
+		}
+
+		// Rows are ordered by table, so a new table starts when the name changes
+		if len(tables) == 0 || tables[len(tables)-1].Schema != t.Schema || tables[len(tables)-1].Table != t.Table {
+			tables = append(tables, &t)
+		}
+
+		current := tables[len(tables)-1]
+		current.Partitions = append(current.Partitions, rangePartition{
+			Name: partitionName,
+			From: unquoteBound(match[1]),
+			To:   unquoteBound(match[2]),
+		})
+	}
+
+	if rows.Err() != nil {
+		fmt.Fprintf(os.Stderr, "Rows iteration failed: %v\n", rows.Err())
+		os.Exit(1)
+	}
+
+	horizon := time.Now().AddDate(0, 0, opts.DaysAhead)
+
+	results := []partitionGapRow{}
+
+	for _, t := range tables {
+		results = append(results, findIssues(t, horizon)...)
+	}
+
+	switch opts.Output {
+	case util.OutputFormatJson:
+		jsonData, _ := json.MarshalIndent(results, "", "  ")
+		fmt.Println(string(jsonData))
+
+	default:
+		schemaDisplay := opts.Schema
+		if opts.Schema == "*" {
+			schemaDisplay = "ALL (except system)"
+		}
+
+		fmt.Printf("Searching for partition gaps in `%s`\n", opts.DbName)
+		fmt.Printf("Schema: %s, Days Ahead: %d (until %s)\n", schemaDisplay, opts.DaysAhead, horizon.Format("2006-01-02"))
+
+		if len(results) == 0 {
+			fmt.Println(strings.Repeat("-", 80))
+			fmt.Println("No gaps or missing future partitions found.")
+			return
+		}
+
+		table := tablewriter.NewWriter(os.Stdout)
+		table.Header([]string{"Schema", "Table", "Issue", "Uncovered Range", "After Partition"})
+
+		for _, row := range results {
+			issueDisplay := string(row.Issue)
+			if row.HasDefault {
+				issueDisplay += " [D]"
+			}
+
+			err := table.Append([]string{
+				row.Schema,
+				row.Table,
+				issueDisplay,
+				fmt.Sprintf("[%s, %s)", row.From, row.To),
+				row.After,
+			})
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error appending table row: %v\n", err)
+			}
+		}
+		if err := table.Render(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error rendering table: %v\n", err)
+		}
+
+		fmt.Println(strings.Repeat("-", 80))
+		fmt.Println("* [D] = Rows in the uncovered range go to the DEFAULT partition. Without it, INSERTs fail.")
+	}
+}
+
+// findIssues sorts the partitions of a table by their lower bound and reports
+// uncovered ranges between them and, for date/time keys, the missing future range.
+func findIssues(t *rangeTable, horizon time.Time) []partitionGapRow {
+	var issues []partitionGapRow
+
+	partitions := t.Partitions
+	var sortErr error
+	sort.SliceStable(partitions, func(i, j int) bool {
+		cmp, err := compareBounds(partitions[i].From, partitions[j].From, t.TypeCategory)
+		if err != nil {
+			sortErr = err
+		}
+		return cmp < 0
+	})
+	if sortErr != nil {
+		fmt.Fprintf(os.Stderr, "Warning: Skipping %s.%s, can't compare partition bounds: %v\n", t.Schema, t.Table, sortErr)
+		return nil
+	}
+
+	newIssue := func(issue issueType, from string, to string, after string) partitionGapRow {
+		return partitionGapRow{
+			Schema:       t.Schema,
+			Table:        t.Table,
+			PartitionKey: t.PartitionKey,
+			Issue:        issue,
+			From:         from,
+			To:           to,
+			After:        after,
+			HasDefault:   t.HasDefault,
+		}
+	}
+
+	for i := 1; i < len(partitions); i++ {
+		prev := partitions[i-1]
+		next := partitions[i]
+
+		// Partitions can't overlap, so a different bound always means a gap
+		if prev.To != next.From {
+			issues = append(issues, newIssue(issueGap, prev.To, next.From, prev.Name))
+		}
+	}
+
+	last := partitions[len(partitions)-1]
+	if t.TypeCategory == "D" && last.To != "MAXVALUE" {
+		lastTo, err := parseTimeBound(last.To)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: Can't parse upper bound of %s.%s: %v\n", t.Schema, last.Name, err)
+		} else if lastTo.Before(horizon) {
+			issues = append(issues, newIssue(issueFuture, last.To, horizon.Format("2006-01-02"), last.Name))
+		}
+	}
+
+	return issues
+}
+
+// unquoteBound turns a bound literal printed by pg_get_expr ('2024-01-01', 100, MINVALUE) into its raw value.
+func unquoteBound(literal string) string {
+	literal = strings.TrimSpace(literal)
+	if len(literal) >= 2 && strings.HasPrefix(literal, "'") && strings.HasSuffix(literal, "'") {
+		return strings.ReplaceAll(literal[1:len(literal)-1], "''", "'")
+	}
+	return literal
+}
+
+// compareBounds compares two bound values according to the key type category.
+// MINVALUE and MAXVALUE sort before and after everything else.
+func compareBounds(a string, b string, typeCategory string) (int, error) {
+	if a == b {
+		return 0, nil
+	}
+	if a == "MINVALUE" || b == "MAXVALUE" {
+		return -1, nil
+	}
+	if a == "MAXVALUE" || b == "MINVALUE" {
+		return 1, nil
+	}
+
+	switch typeCategory {
+	case "N":
+		af, err := strconv.ParseFloat(a, 64)
+		if err != nil {
+			return 0, err
+		}
+		bf, err := strconv.ParseFloat(b, 64)
+		if err != nil {
+			return 0, err
+		}
+		if af < bf {
+			return -1, nil
+		}
+		return 1, nil
+
+	case "D":
+		at, err := parseTimeBound(a)
+		if err != nil {
+			return 0, err
+		}
+		bt, err := parseTimeBound(b)
+		if err != nil {
+			return 0, err
+		}
+		return at.Compare(bt), nil
+
+	default:
+		return strings.Compare(a, b), nil
+	}
+}
+
+// parseTimeBound parses date/timestamp values in the ISO output format of PostgreSQL.
+func parseTimeBound(value string) (time.Time, error) {
+	layouts := []string{
+		"2006-01-02",
+		"2006-01-02 15:04:05",
+		"2006-01-02 15:04:05-07",
+		"2006-01-02 15:04:05-07:00",
+	}
+
+	for _, layout := range layouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("unsupported date/time value '%s'", value)
+}
+
+func printExplanation(sqlQuery string, opts *Options) {
+	fmt.Println("📖 EXPLANATION")
+	fmt.Println("-------------")
+	fmt.Println("RANGE partitions are usually created ahead of time (e.g. one per month) by a cron job or migration.")
+	fmt.Println("When this process breaks, new rows have no partition to go to and INSERTs start failing,")
+	fmt.Println("or silently pile up in the DEFAULT partition.")
+	fmt.Println("")
+
+	fmt.Println("🧠 INTERPRETATION")
+	fmt.Println("-----------------")
+	fmt.Println("• GAP: No partition covers the range between two neighbouring partitions.")
+	fmt.Printf("• NO FUTURE PARTITION: The last partition of a date/time key ends within %d days from now.\n", opts.DaysAhead)
+	fmt.Println("• Scope: Only single column keys are analyzed. Expression keys are skipped.")
+	fmt.Println("• Action: Create the missing partitions (and check the job responsible for creating them).")
+	fmt.Println("")
+
+	fmt.Println("💻 SQL QUERY")
+	fmt.Println("------------")
+	util.PrintRunnableSQL(sqlQuery, []interface{}{opts.Schema, opts.Exclude.Schemas, opts.Exclude.Tables})
+}
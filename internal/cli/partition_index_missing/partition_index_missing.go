@@ -0,0 +1,237 @@
+package partition_index_missing
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/pg-ok/pgok/internal/db"
+	"github.com/pg-ok/pgok/internal/util"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+type Options struct {
+	DbName  string
+	Schema  string
+	Exclude util.Exclusion
+	Explain bool
+	Output  util.OutputFormat
+}
+
+func NewCommand() *cobra.Command {
+	opts := &Options{
+		// Default to scanning all schemas
+		Schema: "*",
+
+		Output: util.OutputFormatTable,
+	}
+
+	command := &cobra.Command{
+		GroupID: "partition",
+
+		Use: "partition:index-missing [db_name]",
+
+		Short: "Find partitions missing indexes that exist on the partitioned parent",
+
+		Args: cobra.ExactArgs(1),
+
+		Run: func(cmd *cobra.Command, args []string) {
+			opts.DbName = args[0]
+			run(opts)
+		},
+	}
+
+	flags := command.Flags()
+	flags.StringVar(&opts.Schema, "schema", opts.Schema, "Schema name (use '*' for all user schemas)")
+	util.AddExclusionFlags(flags, &opts.Exclude)
+	flags.BoolVar(&opts.Explain, "explain", false, "Print the SQL query and explain the logic/interpretation")
+
+	flags.Var(&opts.Output, "output", "Output format (table, json)")
+	_ = command.RegisterFlagCompletionFunc("output", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{"table", "json"}, cobra.ShellCompDirectiveDefault
+	})
+
+	return command
+}
+
+type partitionIndexMissingRow struct {
+	Schema           string `json:"schema"`
+	Table            string `json:"table"`
+	ParentIndex      string `json:"parent_index"`
+	ParentIndexValid bool   `json:"parent_index_valid"`
+	Partition        string `json:"partition"`
+	Definition       string `json:"definition"`
+}
+
+func run(opts *Options) {
+	manager := db.NewDbManager()
+
+	/*
+	 * A partitioned index (relkind 'I') is attached to one index per partition via pg_inherits.
+	 * A partition without such a child index is missing it, which happens when the parent index
+	 * was created with CREATE INDEX ... ON ONLY and the partition indexes were never attached.
+	 */
+	rawSql := `
+       SELECT
+          n.nspname AS schema_name,
+          t.relname AS table_name,
+          ic.relname AS parent_index,
+          pi.indisvalid AS parent_index_valid,
+          tp.oid::REGCLASS::TEXT AS partition_name,
+          pg_get_indexdef(pi.indexrelid) AS definition
+       FROM pg_index AS pi
+       JOIN pg_class AS ic
+         ON ic.oid = pi.indexrelid
+       JOIN pg_class AS t
+         ON t.oid = pi.indrelid
+       JOIN pg_namespace AS n
+         ON n.oid = t.relnamespace
+       JOIN pg_inherits AS ti
+         ON ti.inhparent = pi.indrelid
+       JOIN pg_class AS tp
+         ON tp.oid = ti.inhrelid
+       WHERE 
+          ($1 = '*' OR n.nspname = $1)
+          AND n.nspname NOT IN ('pg_catalog', 'information_schema')
+          AND n.nspname NOT LIKE 'pg_toast%'
+          AND NOT (n.nspname = ANY($2::TEXT[]))
+          AND NOT (t.relname = ANY($3::TEXT[]) OR n.nspname || '.' || t.relname = ANY($3::TEXT[]))
+          AND ic.relkind = 'I' -- Partitioned index
+          AND tp.relkind <> 'f' -- Foreign table partitions can't have indexes
+          AND NOT EXISTS (
+             SELECT 1
+             FROM pg_inherits AS ii
+             JOIN pg_index AS ci
+               ON ci.indexrelid = ii.inhrelid
+             WHERE ii.inhparent = pi.indexrelid
+               AND ci.indrelid = tp.oid
+          )
+       ORDER BY schema_name, table_name, parent_index, partition_name;
+    `
+
+	sqlQuery := util.TrimLeftSpaces(rawSql)
+
+	if opts.Explain {
+		printExplanation(sqlQuery, opts)
+		return
+	}
+
+	ctx := context.Background()
+	conn, err := manager.Connect(ctx, opts.DbName)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error connecting to database: %v\n", err)
+		os.Exit(1)
+	}
+	defer func(conn *pgx.Conn, ctx context.Context) {
+		err := conn.Close(ctx)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error closing connection: %v\n", err)
+		}
+	}(conn, ctx)
+
+	rows, err := conn.Query(ctx, sqlQuery, opts.Schema, opts.Exclude.Schemas, opts.Exclude.Tables)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Query failed: %v\n", err)
+		os.Exit(1)
+	}
+	defer rows.Close()
+
+	var results []partitionIndexMissingRow
+
+	for rows.Next() {
+		var r partitionIndexMissingRow
+
+		err := rows.Scan(
+			&r.Schema,
+			&r.Table,
+			&r.ParentIndex,
+			&r.ParentIndexValid,
+			&r.Partition,
+			&r.Definition,
+		)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Row scan failed: %v\n", err)
+			os.Exit(1)
+		}
+
+		results = append(results, r)
+	}
+
+	if rows.Err() != nil {
+		fmt.Fprintf(os.Stderr, "Rows iteration failed: %v\n", rows.Err())
+		os.Exit(1)
+	}
+
+	switch opts.Output {
+	case util.OutputFormatJson:
+		jsonData, _ := json.MarshalIndent(results, "", "  ")
+		fmt.Println(string(jsonData))
+
+	default:
+		schemaDisplay := opts.Schema
+		if opts.Schema == "*" {
+			schemaDisplay = "ALL (except system)"
+		}
+
+		fmt.Printf("Searching for partitions missing parent indexes in `%s`\n", opts.DbName)
+		fmt.Printf("Schema: %s\n", schemaDisplay)
+
+		if len(results) == 0 {
+			fmt.Println(strings.Repeat("-", 80))
+			fmt.Println("All partitions have the indexes defined on their parent. 👍")
+			return
+		}
+
+		table := tablewriter.NewWriter(os.Stdout)
+		table.Header([]string{"Schema", "Table", "Parent Index", "Missing On Partition"})
+
+		for _, row := range results {
+			parentIndexDisplay := row.ParentIndex
+			if !row.ParentIndexValid {
+				parentIndexDisplay += " [INVALID]"
+			}
+
+			err := table.Append([]string{
+				row.Schema,
+				row.Table,
+				parentIndexDisplay,
+				row.Partition,
+			})
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error appending table row: %v\n", err)
+			}
+		}
+		if err := table.Render(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error rendering table: %v\n", err)
+		}
+
+		fmt.Println(strings.Repeat("-", 80))
+		fmt.Println("* [INVALID] = The parent index stays invalid until every partition has an attached index.")
+		fmt.Println("* Create the index on the partition (CONCURRENTLY) and run ALTER INDEX <parent> ATTACH PARTITION <index>.")
+	}
+}
+
+func printExplanation(sqlQuery string, opts *Options) {
+	fmt.Println("📖 EXPLANATION")
+	fmt.Println("-------------")
+	fmt.Println("An index created on a partitioned table is a 'partitioned index': every partition gets its own")
+	fmt.Println("index attached to it. With CREATE INDEX ... ON ONLY the partition indexes must be created")
+	fmt.Println("and attached manually, which is easy to leave unfinished.")
+	fmt.Println("")
+
+	fmt.Println("🧠 INTERPRETATION")
+	fmt.Println("-----------------")
+	fmt.Println("• Missing: Queries hitting this partition can't use the index and fall back to Sequential Scans.")
+	fmt.Println("• Invalid parent: The parent index is marked invalid until all partitions have an attached index.")
+	fmt.Println("• Action: CREATE INDEX CONCURRENTLY on the partition, then ALTER INDEX ... ATTACH PARTITION ...")
+	fmt.Println("")
+
+	fmt.Println("💻 SQL QUERY")
+	fmt.Println("------------")
+	util.PrintRunnableSQL(sqlQuery, []interface{}{opts.Schema, opts.Exclude.Schemas, opts.Exclude.Tables})
+}
@@ -0,0 +1,267 @@
+package partition_skew
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/pg-ok/pgok/internal/db"
+	"github.com/pg-ok/pgok/internal/util"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+type Options struct {
+	DbName  string
+	Schema  string
+	Exclude util.Exclusion
+	SkewMin float64
+	Explain bool
+	Output  util.OutputFormat
+}
+
+func NewCommand() *cobra.Command {
+	opts := &Options{
+		// Default to scanning all schemas
+		Schema: "*",
+
+		SkewMin: 0.0,
+
+		Output: util.OutputFormatTable,
+	}
+
+	command := &cobra.Command{
+		GroupID: "partition",
+
+		Use: "partition:skew [db_name]",
+
+		Short: "Show partition counts and size skew per partitioned table",
+
+		Args: cobra.ExactArgs(1),
+
+		Run: func(cmd *cobra.Command, args []string) {
+			opts.DbName = args[0]
+			run(opts)
+		},
+	}
+
+	flags := command.Flags()
+	flags.StringVar(&opts.Schema, "schema", opts.Schema, "Schema name (use '*' for all user schemas)")
+	util.AddExclusionFlags(flags, &opts.Exclude)
+	flags.Float64Var(&opts.SkewMin, "skew-min", opts.SkewMin, "Minimum skew (largest partition / average partition size) to include")
+	flags.BoolVar(&opts.Explain, "explain", false, "Print the SQL query and explain the logic/interpretation")
+
+	flags.Var(&opts.Output, "output", "Output format (table, json)")
+	_ = command.RegisterFlagCompletionFunc("output", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{"table", "json"}, cobra.ShellCompDirectiveDefault
+	})
+
+	return command
+}
+
+type partitionSkewRow struct {
+	Schema     string   `json:"schema"`
+	Table      string   `json:"table"`
+	Partitions int64    `json:"partitions"`
+	TotalBytes int64    `json:"total_bytes"`
+	TotalHuman string   `json:"total_human"`
+	MinBytes   int64    `json:"min_bytes"`
+	MinHuman   string   `json:"min_human"`
+	AvgBytes   int64    `json:"avg_bytes"`
+	AvgHuman   string   `json:"avg_human"`
+	MaxBytes   int64    `json:"max_bytes"`
+	MaxHuman   string   `json:"max_human"`
+	Skew       *float64 `json:"skew"` // Pointer to handle NULL (all partitions are empty)
+}
+
+func run(opts *Options) {
+	manager := db.NewDbManager()
+
+	/*
+	 * Sizes are calculated for direct partitions of top-level partitioned tables.
+	 * A sub-partitioned partition counts with the total size of its whole tree.
+	 */
+	rawSql := `
+       WITH partition_stats AS (
+          SELECT
+             n.nspname AS schema_name,
+             c.relname AS table_name,
+             COUNT(*) AS partitions,
+             SUM(ps.size_bytes)::BIGINT AS total_bytes,
+             MIN(ps.size_bytes)::BIGINT AS min_bytes,
+             AVG(ps.size_bytes)::BIGINT AS avg_bytes,
+             MAX(ps.size_bytes)::BIGINT AS max_bytes
+          FROM pg_partitioned_table AS pt
+          JOIN pg_class AS c
+            ON c.oid = pt.partrelid
+          JOIN pg_namespace AS n
+            ON n.oid = c.relnamespace
+          JOIN pg_inherits AS i
+            ON i.inhparent = c.oid
+          -- Sizes are computed only for the tables left after the filters below
+          CROSS JOIN LATERAL (
+             SELECT SUM(pg_total_relation_size(t.relid))::BIGINT AS size_bytes
+             FROM pg_partition_tree(i.inhrelid) AS t
+          ) AS ps
+          WHERE 
+             ($1 = '*' OR n.nspname = $1)
+             AND n.nspname NOT IN ('pg_catalog', 'information_schema')
+             AND n.nspname NOT LIKE 'pg_toast%'
+             AND NOT (n.nspname = ANY($3::TEXT[]))
+             AND NOT (c.relname = ANY($4::TEXT[]) OR n.nspname || '.' || c.relname = ANY($4::TEXT[]))
+             AND NOT c.relispartition -- Top-level partitioned tables only
+          GROUP BY n.nspname, c.relname
+       )
+       SELECT
+          schema_name,
+          table_name,
+          partitions,
+          total_bytes,
+          pg_size_pretty(total_bytes) AS total_human,
+          min_bytes,
+          pg_size_pretty(min_bytes) AS min_human,
+          avg_bytes,
+          pg_size_pretty(avg_bytes) AS avg_human,
+          max_bytes,
+          pg_size_pretty(max_bytes) AS max_human,
+          ROUND(max_bytes::NUMERIC / NULLIF(avg_bytes, 0), 2)::FLOAT AS skew
+       FROM partition_stats
+       WHERE COALESCE(ROUND(max_bytes::NUMERIC / NULLIF(avg_bytes, 0), 2), 0) >= $2
+       ORDER BY skew DESC NULLS LAST, total_bytes DESC;
+    `
+
+	sqlQuery := util.TrimLeftSpaces(rawSql)
+
+	if opts.Explain {
+		printExplanation(sqlQuery, opts)
+		return
+	}
+
+	ctx := context.Background()
+	conn, err := manager.Connect(ctx, opts.DbName)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error connecting to database: %v\n", err)
+		os.Exit(1)
+	}
+	defer func(conn *pgx.Conn, ctx context.Context) {
+		err := conn.Close(ctx)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error closing connection: %v\n", err)
+		}
+	}(conn, ctx)
+
+	rows, err := conn.Query(ctx, sqlQuery, opts.Schema, opts.SkewMin, opts.Exclude.Schemas, opts.Exclude.Tables)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Query failed: %v\n", err)
+		os.Exit(1)
+	}
+	defer rows.Close()
+
+	var results []partitionSkewRow
+
+	for rows.Next() {
+		var r partitionSkewRow
+
+		err := rows.Scan(
+			&r.Schema,
+			&r.Table,
+			&r.Partitions,
+			&r.TotalBytes,
+			&r.TotalHuman,
+			&r.MinBytes,
+			&r.MinHuman,
+			&r.AvgBytes,
+			&r.AvgHuman,
+			&r.MaxBytes,
+			&r.MaxHuman,
+			&r.Skew,
+		)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Row scan failed: %v\n", err)
+			os.Exit(1)
+		}
+
+		results = append(results, r)
+	}
+
+	if rows.Err() != nil {
+		fmt.Fprintf(os.Stderr, "Rows iteration failed: %v\n", rows.Err())
+		os.Exit(1)
+	}
+
+	switch opts.Output {
+	case util.OutputFormatJson:
+		jsonData, _ := json.MarshalIndent(results, "", "  ")
+		fmt.Println(string(jsonData))
+
+	default:
+		schemaDisplay := opts.Schema
+		if opts.Schema == "*" {
+			schemaDisplay = "ALL (except system)"
+		}
+
+		fmt.Printf("Analyzing partition skew in `%s`\n", opts.DbName)
+		fmt.Printf("Schema: %s, Skew Min: >= %.2f\n", schemaDisplay, opts.SkewMin)
+
+		if len(results) == 0 {
+			fmt.Println(strings.Repeat("-", 80))
+			fmt.Println("No partitioned tables found within the specified criteria.")
+			return
+		}
+
+		table := tablewriter.NewWriter(os.Stdout)
+		table.Header([]string{"Schema", "Table", "Partitions", "Total", "Min", "Avg", "Max", "Skew"})
+
+		for _, row := range results {
+			skewDisplay := "-"
+			if row.Skew != nil {
+				skewDisplay = fmt.Sprintf("%.2f", *row.Skew)
+			}
+
+			err := table.Append([]string{
+				row.Schema,
+				row.Table,
+				fmt.Sprintf("%d", row.Partitions),
+				row.TotalHuman,
+				row.MinHuman,
+				row.AvgHuman,
+				row.MaxHuman,
+				skewDisplay,
+			})
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error appending table row: %v\n", err)
+			}
+		}
+		if err := table.Render(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error rendering table: %v\n", err)
+		}
+
+		fmt.Println(strings.Repeat("-", 80))
+		fmt.Println("* Skew = Largest partition / average partition size. 1.00 means evenly distributed partitions.")
+	}
+}
+
+func printExplanation(sqlQuery string, opts *Options) {
+	fmt.Println("📖 EXPLANATION")
+	fmt.Println("-------------")
+	fmt.Println("Partitioning works best when data is spread evenly and partition counts stay reasonable.")
+	fmt.Println("A single huge partition (e.g. a DEFAULT partition collecting everything, or a 'hot' tenant)")
+	fmt.Println("brings back the problems partitioning was supposed to solve.")
+	fmt.Println("")
+
+	fmt.Println("🧠 INTERPRETATION")
+	fmt.Println("-----------------")
+	fmt.Println("• Skew: Largest partition divided by the average. High values mean one partition dominates.")
+	fmt.Println("• Partitions: Thousands of partitions slow down planning and increase lock/memory usage.")
+	fmt.Println("• Note: For time-based RANGE partitioning, empty future partitions lower the average (expected).")
+	fmt.Println("• Action: Review the partition key/boundaries, split the largest partition, or drop old ones.")
+	fmt.Println("")
+
+	fmt.Println("💻 SQL QUERY")
+	fmt.Println("------------")
+	util.PrintRunnableSQL(sqlQuery, []interface{}{opts.Schema, opts.SkewMin, opts.Exclude.Schemas, opts.Exclude.Tables})
+}
@@ -0,0 +1,254 @@
+package query_top
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/pg-ok/pgok/internal/db"
+	"github.com/pg-ok/pgok/internal/util"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+type Options struct {
+	DbName  string
+	Limit   int64
+	SortBy  string
+	Explain bool
+	Output  util.OutputFormat
+}
+
+// sortColumns maps --sort-by values to result columns.
+var sortColumns = map[string]string{
+	"total-time":        "total_time_ms",
+	"mean-time":         "mean_time_ms",
+	"calls":             "calls",
+	"rows":              "rows",
+	"shared-blks-read":  "shared_blks_read",
+	"temp-blks-written": "temp_blks_written",
+}
+
+var sortValues = []string{"total-time", "mean-time", "calls", "rows", "shared-blks-read", "temp-blks-written"}
+
+func NewCommand() *cobra.Command {
+	opts := &Options{
+		Limit: 20,
+
+		SortBy: "total-time",
+
+		Output: util.OutputFormatTable,
+	}
+
+	command := &cobra.Command{
+		GroupID: "query",
+
+		Use: "query:top [db_name]",
+
+		Short: "Show top queries from pg_stat_statements",
+
+		Long: `Show top normalized queries of the current database from pg_stat_statements.
+Requires the pg_stat_statements extension to be installed and loaded via shared_preload_libraries.`,
+
+		Args: cobra.ExactArgs(1),
+
+		Run: func(cmd *cobra.Command, args []string) {
+			opts.DbName = args[0]
+
+			if _, ok := sortColumns[opts.SortBy]; !ok {
+				fmt.Fprintf(os.Stderr, "Error: --sort-by must be one of: %s\n", strings.Join(sortValues, ", "))
+				os.Exit(1)
+			}
+
+			run(opts)
+		},
+	}
+
+	flags := command.Flags()
+	flags.Int64Var(&opts.Limit, "limit", opts.Limit, "Maximum number of queries to show")
+	flags.StringVar(&opts.SortBy, "sort-by", opts.SortBy, "Sort by: "+strings.Join(sortValues, ", "))
+	_ = command.RegisterFlagCompletionFunc("sort-by", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return sortValues, cobra.ShellCompDirectiveDefault
+	})
+	flags.BoolVar(&opts.Explain, "explain", false, "Print the SQL query and explain the logic/interpretation")
+
+	flags.Var(&opts.Output, "output", "Output format (table, json)")
+	_ = command.RegisterFlagCompletionFunc("output", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{"table", "json"}, cobra.ShellCompDirectiveDefault
+	})
+
+	return command
+}
+
+type topQueryRow struct {
+	QueryId          *int64  `json:"query_id"` // Pointer to handle NULL (compute_query_id = off)
+	Query            string  `json:"query"`
+	Calls            int64   `json:"calls"`
+	TotalTimeMs      float64 `json:"total_time_ms"`
+	MeanTimeMs       float64 `json:"mean_time_ms"`
+	TotalTimePercent float64 `json:"total_time_percent"`
+	Rows             int64   `json:"rows"`
+	SharedBlksRead   int64   `json:"shared_blks_read"`
+	TempBlksWritten  int64   `json:"temp_blks_written"`
+}
+
+// buildSql renders the query for the detected pg_stat_statements layout.
+func buildSql(statements *db.StatStatements, opts *Options) string {
+	rawSql := `
+       SELECT
+          queryid AS query_id,
+          query,
+          calls,
+          ROUND(%[2]s::NUMERIC, 2)::FLOAT AS total_time_ms,
+          ROUND(%[3]s::NUMERIC, 2)::FLOAT AS mean_time_ms,
+          ROUND(
+             COALESCE((%[2]s / NULLIF(SUM(%[2]s) OVER (), 0)) * 100.0, 0.0)::NUMERIC,
+             2
+          )::FLOAT AS total_time_percent,
+          rows,
+          shared_blks_read,
+          temp_blks_written
+       FROM %[1]s
+       WHERE dbid = (SELECT oid FROM pg_database WHERE datname = current_database())
+       ORDER BY %[4]s DESC
+       LIMIT $1;
+    `
+
+	return util.TrimLeftSpaces(fmt.Sprintf(
+		rawSql,
+		statements.View,
+		statements.TotalTimeColumn,
+		statements.MeanTimeColumn,
+		sortColumns[opts.SortBy],
+	))
+}
+
+func run(opts *Options) {
+	manager := db.NewDbManager()
+
+	if opts.Explain {
+		printExplanation(buildSql(db.DefaultStatStatements, opts), opts)
+		return
+	}
+
+	ctx := context.Background()
+	conn, err := manager.Connect(ctx, opts.DbName)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error connecting to database: %v\n", err)
+		os.Exit(1)
+	}
+	defer func(conn *pgx.Conn, ctx context.Context) {
+		err := conn.Close(ctx)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error closing connection: %v\n", err)
+		}
+	}(conn, ctx)
+
+	statements, err := db.GetStatStatements(ctx, conn)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	rows, err := conn.Query(ctx, buildSql(statements, opts), opts.Limit)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Query failed: %v\n", err)
+		os.Exit(1)
+	}
+	defer rows.Close()
+
+	var results []topQueryRow
+
+	for rows.Next() {
+		var r topQueryRow
+
+		err := rows.Scan(
+			&r.QueryId,
+			&r.Query,
+			&r.Calls,
+			&r.TotalTimeMs,
+			&r.MeanTimeMs,
+			&r.TotalTimePercent,
+			&r.Rows,
+			&r.SharedBlksRead,
+			&r.TempBlksWritten,
+		)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Row scan failed: %v\n", err)
+			os.Exit(1)
+		}
+
+		results = append(results, r)
+	}
+
+	if rows.Err() != nil {
+		fmt.Fprintf(os.Stderr, "Rows iteration failed: %v\n", rows.Err())
+		os.Exit(1)
+	}
+
+	switch opts.Output {
+	case util.OutputFormatJson:
+		jsonData, _ := json.MarshalIndent(results, "", "  ")
+		fmt.Println(string(jsonData))
+
+	default:
+		fmt.Printf("Top queries in `%s`\n", opts.DbName)
+		fmt.Printf("Sort By: %s, Limit: %d\n", opts.SortBy, opts.Limit)
+
+		table := tablewriter.NewWriter(os.Stdout)
+		table.Header([]string{"Query", "Calls", "Total ms (%)", "Mean ms", "Rows", "Shared Reads", "Temp Written"})
+
+		for _, row := range results {
+			// Normalize whitespace and truncate the query for display purposes only
+			queryDisplay := strings.Join(strings.Fields(row.Query), " ")
+			if len(queryDisplay) > 60 {
+				queryDisplay = queryDisplay[0:57] + "..."
+			}
+
+			err := table.Append([]string{
+				queryDisplay,
+				fmt.Sprintf("%d", row.Calls),
+				fmt.Sprintf("%.0f (%.2f%%)", row.TotalTimeMs, row.TotalTimePercent),
+				fmt.Sprintf("%.2f", row.MeanTimeMs),
+				fmt.Sprintf("%d", row.Rows),
+				fmt.Sprintf("%d", row.SharedBlksRead),
+				fmt.Sprintf("%d", row.TempBlksWritten),
+			})
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error appending table row: %v\n", err)
+			}
+		}
+		if err := table.Render(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error rendering table: %v\n", err)
+		}
+
+		fmt.Println(strings.Repeat("-", 80))
+		fmt.Println("* Queries are truncated. Use --output=json to get the full normalized query text.")
+		fmt.Println("* Statistics are cumulative since pg_stat_statements_reset() or server start.")
+	}
+}
+
+func printExplanation(sqlQuery string, opts *Options) {
+	fmt.Println("📖 EXPLANATION")
+	fmt.Println("-------------")
+	fmt.Println("pg_stat_statements tracks execution statistics of all normalized queries (constants replaced by $N).")
+	fmt.Println("It is the most reliable way to find which queries consume the server resources.")
+	fmt.Println("")
+
+	fmt.Println("🧠 INTERPRETATION")
+	fmt.Println("-----------------")
+	fmt.Println("• Total ms: Overall time spent. Optimizing the top queries gives the biggest win.")
+	fmt.Println("• Mean ms: Time per call. High values hurt latency even for rare queries.")
+	fmt.Println("• Shared Reads: Blocks read from disk (or OS cache), not found in shared_buffers.")
+	fmt.Println("• Temp Written: Blocks spilled to temporary files (sorts, hashes). Consider raising work_mem.")
+	fmt.Println("• Compatibility: On PG12 the columns are total_time/mean_time, detected automatically.")
+	fmt.Println("")
+
+	fmt.Println("💻 SQL QUERY")
+	fmt.Println("------------")
+	util.PrintRunnableSQL(sqlQuery, []interface{}{opts.Limit})
+}
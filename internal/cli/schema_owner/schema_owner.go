@@ -5,8 +5,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"sort"
 	"strings"
 
+	"github.com/pg-ok/pgok/internal/config"
 	"github.com/pg-ok/pgok/internal/db"
 	"github.com/pg-ok/pgok/internal/util"
 
@@ -16,11 +18,13 @@ import (
 )
 
 type Options struct {
-	DbName        string
-	Schema        string
-	ExpectedOwner string
-	Explain       bool
-	Output        util.OutputFormat
+	DbName           string
+	Schema           string
+	Exclude          util.Exclusion
+	ExpectedOwners   []string
+	ExpectedMemberOf []string
+	Explain          bool
+	Output           util.OutputFormat
 }
 
 func NewCommand() *cobra.Command {
@@ -36,9 +40,11 @@ func NewCommand() *cobra.Command {
 
 		Use: "schema:owner [db_name]",
 
-		Short: "Detect objects owned by unexpected users (Tables, Enums, Sequences...)",
+		Short: "Detect objects owned by unexpected users (Schemas, Tables, Functions, Types...)",
 
-		Long: "Lists database objects (Tables, Views, Sequences, Enums, Domains) that are NOT owned by the specified user.",
+		Long: `Lists database objects (Schemas, Tables, Views, Sequences, Types, Functions, Procedures, Aggregates, Event Triggers, Publications) that are NOT owned by the expected users.
+An owner is accepted when it is one of --expected, or a member of one of --expected-member-of roles.
+Per-schema expected owners can be defined in pgok.toml ([schema_owner.schemas.<name>]), they replace the flags for that schema.`,
 
 		Args: cobra.ExactArgs(1),
 
@@ -50,10 +56,11 @@ func NewCommand() *cobra.Command {
 
 	flags := command.Flags()
 
-	flags.StringVar(&opts.ExpectedOwner, "expected", "", "The username that SHOULD own the objects")
-	_ = command.MarkFlagRequired("expected")
+	flags.StringSliceVar(&opts.ExpectedOwners, "expected", nil, "The usernames allowed to own the objects (comma-separated or repeated)")
+	flags.StringSliceVar(&opts.ExpectedMemberOf, "expected-member-of", nil, "Allow owners that are members of these roles (comma-separated or repeated)")
 
 	flags.StringVar(&opts.Schema, "schema", opts.Schema, "Schema name (use '*' for all user schemas)")
+	util.AddExclusionFlags(flags, &opts.Exclude)
 	flags.BoolVar(&opts.Explain, "explain", false, "Print the SQL query and explain the logic/interpretation")
 
 	flags.Var(&opts.Output, "output", "Output format (table, json)")
@@ -65,69 +72,226 @@ func NewCommand() *cobra.Command {
 }
 
 type ownerRow struct {
-	SchemaName  string `json:"schema_name"`
-	ObjectName  string `json:"object_name"`
-	ObjectType  string `json:"object_type"`
-	ActualOwner string `json:"actual_owner"`
-	FixCommand  string `json:"fix_command"`
+	SchemaName       string   `json:"schema_name"`
+	ObjectName       string   `json:"object_name"`
+	ObjectType       string   `json:"object_type"`
+	AlterKeyword     string   `json:"-"`
+	ObjectIdentity   string   `json:"-"`
+	ActualOwner      string   `json:"actual_owner"`
+	ExpectedOwners   []string `json:"expected_owners"`
+	ExpectedMemberOf []string `json:"expected_member_of"`
+	FixCommand       string   `json:"fix_command"`
 }
 
 func run(opts *Options) {
 	manager := db.NewDbManager()
 
-	// Union pg_class (tables/views/seqs) and pg_type (enums/domains)
+	// Union all ownable objects: schemas, relations, types, routines and database-level objects.
+	// object_identity is the quoted name used in the fix command (with identity arguments for routines).
+	// Database-level objects (event triggers, publications) have an empty schema_name.
+	// Expected owners come from per-schema rules ($4, JSONB) or from the flags ($2, $3) otherwise.
+	// Objects without any applicable rule are not checked.
 	rawSql := `
-       SELECT schema_name, object_name, object_type, actual_owner
+       SELECT schema_name, object_name, object_type, alter_keyword, object_identity, actual_owner, expected_owners, expected_member_of
        FROM (
-          -- 1. Relations (Tables, Sequences, Views, MatViews)
           SELECT
-             c.relname AS object_name,
-             CASE c.relkind
-                WHEN 'r' THEN 'TABLE'
-                WHEN 'v' THEN 'VIEW'
-                WHEN 'm' THEN 'MATERIALIZED VIEW'
-                WHEN 'S' THEN 'SEQUENCE'
-                WHEN 'f' THEN 'FOREIGN TABLE'
-                WHEN 'p' THEN 'PARTITIONED TABLE'
-                ELSE 'UNKNOWN (' || c.relkind::text || ')'
-             END AS object_type,
-             r.rolname AS actual_owner,
-             n.nspname AS schema_name
-          FROM pg_class c
-          JOIN pg_roles r ON r.oid = c.relowner
-          JOIN pg_namespace n ON n.oid = c.relnamespace
-          WHERE c.relkind IN ('r', 'v', 'm', 'S', 'f', 'p')
-
-          UNION ALL
-
-          -- 2. Types (Enums, Domains)
-          -- typtype: e=enum, d=domain. (b=base, c=composite skipping)
-          SELECT
-             t.typname AS object_name,
-             CASE t.typtype
-                WHEN 'e' THEN 'TYPE'   -- ENUM is handled via ALTER TYPE
-                WHEN 'd' THEN 'DOMAIN' -- DOMAIN is handled via ALTER DOMAIN
-                ELSE 'TYPE'
-             END AS object_type,
-             r.rolname AS actual_owner,
-             n.nspname AS schema_name
-          FROM pg_type t
-          JOIN pg_roles r ON r.oid = t.typowner
-          JOIN pg_namespace n ON n.oid = t.typnamespace
-          WHERE t.typtype IN ('e', 'd')
-       ) AS all_objects
+             all_objects.*,
+             CASE
+                WHEN $4::JSONB ? schema_name
+                THEN ARRAY(SELECT jsonb_array_elements_text($4::JSONB -> schema_name -> 'expected'))
+                ELSE $2::TEXT[]
+             END AS expected_owners,
+             CASE
+                WHEN $4::JSONB ? schema_name
+                THEN ARRAY(SELECT jsonb_array_elements_text($4::JSONB -> schema_name -> 'expected_member_of'))
+                ELSE $3::TEXT[]
+             END AS expected_member_of
+          FROM (
+             -- 1. Schemas
+             SELECT
+                n.nspname AS object_name,
+                'SCHEMA' AS object_type,
+                'SCHEMA' AS alter_keyword,
+                quote_ident(n.nspname) AS object_identity,
+                r.rolname AS actual_owner,
+                n.nspname AS schema_name
+             FROM pg_namespace n
+             JOIN pg_roles r ON r.oid = n.nspowner
+
+             UNION ALL
+
+             -- 2. Relations (Tables, Sequences, Views, MatViews)
+             SELECT
+                c.relname AS object_name,
+                CASE c.relkind
+                   WHEN 'r' THEN 'TABLE'
+                   WHEN 'v' THEN 'VIEW'
+                   WHEN 'm' THEN 'MATERIALIZED VIEW'
+                   WHEN 'S' THEN 'SEQUENCE'
+                   WHEN 'f' THEN 'FOREIGN TABLE'
+                   WHEN 'p' THEN 'PARTITIONED TABLE'
+                   ELSE 'UNKNOWN (' || c.relkind::text || ')'
+                END AS object_type,
+                CASE c.relkind
+                   WHEN 'v' THEN 'VIEW'
+                   WHEN 'm' THEN 'MATERIALIZED VIEW'
+                   WHEN 'S' THEN 'SEQUENCE'
+                   WHEN 'f' THEN 'FOREIGN TABLE'
+                   ELSE 'TABLE'
+                END AS alter_keyword,
+                quote_ident(n.nspname) || '.' || quote_ident(c.relname) AS object_identity,
+                r.rolname AS actual_owner,
+                n.nspname AS schema_name
+             FROM pg_class c
+             JOIN pg_roles r ON r.oid = c.relowner
+             JOIN pg_namespace n ON n.oid = c.relnamespace
+             WHERE c.relkind IN ('r', 'v', 'm', 'S', 'f', 'p')
+
+             UNION ALL
+
+             -- 3. Types (Enums, Domains, Composite types)
+             -- typtype: e=enum, d=domain, c=composite (only standalone ones, not row types of tables). b=base is skipped
+             SELECT
+                t.typname AS object_name,
+                CASE t.typtype
+                   WHEN 'e' THEN 'TYPE'
+                   WHEN 'd' THEN 'DOMAIN'
+                   WHEN 'c' THEN 'COMPOSITE TYPE'
+                   ELSE 'TYPE'
+                END AS object_type,
+                CASE t.typtype
+                   WHEN 'd' THEN 'DOMAIN' -- DOMAIN is handled via ALTER DOMAIN
+                   ELSE 'TYPE'            -- ENUM and composite types are handled via ALTER TYPE
+                END AS alter_keyword,
+                quote_ident(n.nspname) || '.' || quote_ident(t.typname) AS object_identity,
+                r.rolname AS actual_owner,
+                n.nspname AS schema_name
+             FROM pg_type t
+             JOIN pg_roles r ON r.oid = t.typowner
+             JOIN pg_namespace n ON n.oid = t.typnamespace
+             LEFT JOIN pg_class tc ON tc.oid = t.typrelid
+             WHERE (t.typtype IN ('e', 'd') OR (t.typtype = 'c' AND tc.relkind = 'c'))
+               AND NOT EXISTS (
+                  SELECT 1 FROM pg_depend d
+                  WHERE d.classid = 'pg_type'::regclass AND d.objid = t.oid AND d.deptype = 'e'
+               )
+
+             UNION ALL
+
+             -- 4. Routines (Functions, Procedures, Aggregates)
+             -- Extension members are skipped: they are owned by whoever ran CREATE EXTENSION
+             SELECT
+                p.proname || '(' || pg_get_function_identity_arguments(p.oid) || ')' AS object_name,
+                CASE p.prokind
+                   WHEN 'p' THEN 'PROCEDURE'
+                   WHEN 'a' THEN 'AGGREGATE'
+                   ELSE 'FUNCTION'
+                END AS object_type,
+                CASE p.prokind
+                   WHEN 'p' THEN 'PROCEDURE'
+                   WHEN 'a' THEN 'AGGREGATE'
+                   ELSE 'FUNCTION'
+                END AS alter_keyword,
+                quote_ident(n.nspname) || '.' || quote_ident(p.proname)
+                   || '(' || pg_get_function_identity_arguments(p.oid) || ')' AS object_identity,
+                r.rolname AS actual_owner,
+                n.nspname AS schema_name
+             FROM pg_proc p
+             JOIN pg_roles r ON r.oid = p.proowner
+             JOIN pg_namespace n ON n.oid = p.pronamespace
+             WHERE NOT EXISTS (
+                SELECT 1 FROM pg_depend d
+                WHERE d.classid = 'pg_proc'::regclass AND d.objid = p.oid AND d.deptype = 'e'
+             )
+
+             UNION ALL
+
+             -- 5. Event Triggers (database-level)
+             SELECT
+                e.evtname AS object_name,
+                'EVENT TRIGGER' AS object_type,
+                'EVENT TRIGGER' AS alter_keyword,
+                quote_ident(e.evtname) AS object_identity,
+                r.rolname AS actual_owner,
+                '' AS schema_name
+             FROM pg_event_trigger e
+             JOIN pg_roles r ON r.oid = e.evtowner
+
+             UNION ALL
+
+             -- 6. Publications (database-level)
+             SELECT
+                pub.pubname AS object_name,
+                'PUBLICATION' AS object_type,
+                'PUBLICATION' AS alter_keyword,
+                quote_ident(pub.pubname) AS object_identity,
+                r.rolname AS actual_owner,
+                '' AS schema_name
+             FROM pg_publication pub
+             JOIN pg_roles r ON r.oid = pub.pubowner
+          ) AS all_objects
+       ) AS checked_objects
        WHERE 
          ($1 = '*' OR schema_name = $1)
          AND schema_name NOT IN ('pg_catalog', 'information_schema')
          AND schema_name NOT LIKE 'pg_toast%'
-         AND actual_owner != $2
+         AND schema_name NOT LIKE 'pg_temp%'
+         AND NOT (schema_name = ANY($5::TEXT[]))
+         AND NOT (object_name = ANY($6::TEXT[]) OR schema_name || '.' || object_name = ANY($6::TEXT[]))
+         AND cardinality(expected_owners) + cardinality(expected_member_of) > 0
+         AND actual_owner <> ALL(expected_owners)
+         AND NOT EXISTS (
+            SELECT 1
+            FROM UNNEST(expected_member_of) AS m(role_name)
+            JOIN pg_roles AS g ON g.rolname = m.role_name
+            WHERE pg_has_role(actual_owner, g.oid, 'MEMBER')
+         )
        ORDER BY schema_name, object_type, object_name;
     `
 
+	// Typos in role names would otherwise flag every object (or fail pg_has_role)
+	rawUnknownRolesSql := `
+       SELECT u.role_name
+       FROM UNNEST($1::TEXT[]) AS u(role_name)
+       WHERE NOT EXISTS (
+          SELECT 1 FROM pg_roles AS r WHERE r.rolname = u.role_name
+       )
+       ORDER BY u.role_name;
+    `
+
 	sqlQuery := util.TrimLeftSpaces(rawSql)
+	unknownRolesSqlQuery := util.TrimLeftSpaces(rawUnknownRolesSql)
+
+	// Empty slices instead of nil, so they are passed as '{}' and not NULL
+	if opts.ExpectedOwners == nil {
+		opts.ExpectedOwners = []string{}
+	}
+	if opts.ExpectedMemberOf == nil {
+		opts.ExpectedMemberOf = []string{}
+	}
+
+	// Missing lists are passed as [] (not null), so jsonb_array_elements_text() can expand them
+	schemaRules := map[string]config.OwnerRule{}
+	for name, rule := range manager.GetConfigSchemaOwners() {
+		if rule.Expected == nil {
+			rule.Expected = []string{}
+		}
+		if rule.ExpectedMemberOf == nil {
+			rule.ExpectedMemberOf = []string{}
+		}
+		schemaRules[name] = rule
+	}
+	schemaRulesJson, _ := json.Marshal(schemaRules)
+
+	if len(opts.ExpectedOwners) == 0 && len(opts.ExpectedMemberOf) == 0 && len(schemaRules) == 0 {
+		fmt.Fprintln(os.Stderr, "Error: --expected or --expected-member-of is required (or define [schema_owner.schemas.<name>] in pgok.toml)")
+		os.Exit(1)
+	}
+
+	roleNames := collectRoleNames(opts, schemaRules)
 
 	if opts.Explain {
-		printExplanation(sqlQuery, opts)
+		printExplanation(sqlQuery, unknownRolesSqlQuery, roleNames, string(schemaRulesJson), opts)
 		return
 	}
 
@@ -144,7 +308,13 @@ func run(opts *Options) {
 		}
 	}(conn, ctx)
 
-	rows, err := conn.Query(ctx, sqlQuery, opts.Schema, opts.ExpectedOwner)
+	unknownRoles := queryUnknownRoles(ctx, conn, unknownRolesSqlQuery, roleNames)
+	if len(unknownRoles) > 0 {
+		fmt.Fprintf(os.Stderr, "Error: unknown role(s) in expected owners: %s\n", strings.Join(unknownRoles, ", "))
+		os.Exit(1)
+	}
+
+	rows, err := conn.Query(ctx, sqlQuery, opts.Schema, opts.ExpectedOwners, opts.ExpectedMemberOf, string(schemaRulesJson), opts.Exclude.Schemas, opts.Exclude.Tables)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Query failed: %v\n", err)
 		os.Exit(1)
@@ -155,14 +325,29 @@ func run(opts *Options) {
 
 	for rows.Next() {
 		var r ownerRow
-		err := rows.Scan(&r.SchemaName, &r.ObjectName, &r.ObjectType, &r.ActualOwner)
+		err := rows.Scan(
+			&r.SchemaName,
+			&r.ObjectName,
+			&r.ObjectType,
+			&r.AlterKeyword,
+			&r.ObjectIdentity,
+			&r.ActualOwner,
+			&r.ExpectedOwners,
+			&r.ExpectedMemberOf,
+		)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Row scan failed: %v\n", err)
 			os.Exit(1)
 		}
 
-		cmdType := r.ObjectType
-		r.FixCommand = fmt.Sprintf("ALTER %s %s.%s OWNER TO %s;", cmdType, r.SchemaName, r.ObjectName, opts.ExpectedOwner)
+		// Prefer an explicit owner; a group role itself also satisfies "member of"
+		newOwner := ""
+		if len(r.ExpectedOwners) > 0 {
+			newOwner = r.ExpectedOwners[0]
+		} else if len(r.ExpectedMemberOf) > 0 {
+			newOwner = r.ExpectedMemberOf[0]
+		}
+		r.FixCommand = fmt.Sprintf("ALTER %s %s OWNER TO %s;", r.AlterKeyword, r.ObjectIdentity, pgx.Identifier{newOwner}.Sanitize())
 
 		results = append(results, r)
 	}
@@ -183,18 +368,22 @@ func run(opts *Options) {
 			schemaDisplay = "ALL (except system)"
 		}
 
-		fmt.Printf("Checking schema ownership in `%s` (Expected: %s)\n", opts.DbName, opts.ExpectedOwner)
+		fmt.Printf("Checking schema ownership in `%s` (Expected: %s)\n", opts.DbName, formatExpected(opts.ExpectedOwners, opts.ExpectedMemberOf))
 		fmt.Printf("Schema: %s\n", schemaDisplay)
+		for _, name := range sortedKeys(schemaRules) {
+			rule := schemaRules[name]
+			fmt.Printf("Schema Rule: %s -> %s\n", name, formatExpected(rule.Expected, rule.ExpectedMemberOf))
+		}
 
 		if len(results) == 0 {
 			fmt.Println(strings.Repeat("-", 80))
-			fmt.Printf("All objects (Tables, Types, Seqs) are correctly owned by '%s'. Good job! ✨\n", opts.ExpectedOwner)
+			fmt.Println("All objects (Schemas, Tables, Types, Functions...) are correctly owned. Good job! ✨")
 			fmt.Println(strings.Repeat("-", 80))
 			return
 		}
 
 		table := tablewriter.NewWriter(os.Stdout)
-		table.Header([]string{"Schema", "Type", "Object", "Current Owner", "Fix Command"})
+		table.Header([]string{"Schema", "Type", "Object", "Current Owner", "Expected", "Fix Command"})
 
 		for _, row := range results {
 			err := table.Append([]string{
@@ -202,6 +391,7 @@ func run(opts *Options) {
 				row.ObjectType,
 				row.ObjectName,
 				row.ActualOwner,
+				formatExpected(row.ExpectedOwners, row.ExpectedMemberOf),
 				row.FixCommand,
 			})
 			if err != nil {
@@ -218,7 +408,81 @@ func run(opts *Options) {
 	}
 }
 
-func printExplanation(sqlQuery string, opts *Options) {
+// formatExpected renders allowed owners, e.g. "deploy, postgres, member of app_owner".
+func formatExpected(owners []string, memberOf []string) string {
+	parts := append([]string{}, owners...)
+	for _, role := range memberOf {
+		parts = append(parts, "member of "+role)
+	}
+
+	if len(parts) == 0 {
+		return "-"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// collectRoleNames returns all distinct roles named by the flags and the per-schema rules.
+func collectRoleNames(opts *Options, schemaRules map[string]config.OwnerRule) []string {
+	seen := map[string]bool{}
+	var names []string
+
+	add := func(roles []string) {
+		for _, role := range roles {
+			if !seen[role] {
+				seen[role] = true
+				names = append(names, role)
+			}
+		}
+	}
+
+	add(opts.ExpectedOwners)
+	add(opts.ExpectedMemberOf)
+	for _, name := range sortedKeys(schemaRules) {
+		add(schemaRules[name].Expected)
+		add(schemaRules[name].ExpectedMemberOf)
+	}
+
+	sort.Strings(names)
+	return names
+}
+
+func queryUnknownRoles(ctx context.Context, conn *pgx.Conn, sqlQuery string, roleNames []string) []string {
+	rows, err := conn.Query(ctx, sqlQuery, roleNames)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Query failed: %v\n", err)
+		os.Exit(1)
+	}
+	defer rows.Close()
+
+	var results []string
+
+	for rows.Next() {
+		var role string
+		if err := rows.Scan(&role); err != nil {
+			fmt.Fprintf(os.Stderr, "Row scan failed: %v\n", err)
+			os.Exit(1)
+		}
+		results = append(results, role)
+	}
+
+	if rows.Err() != nil {
+		fmt.Fprintf(os.Stderr, "Rows iteration failed: %v\n", rows.Err())
+		os.Exit(1)
+	}
+
+	return results
+}
+
+func sortedKeys(rules map[string]config.OwnerRule) []string {
+	keys := make([]string, 0, len(rules))
+	for k := range rules {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func printExplanation(sqlQuery string, unknownRolesSqlQuery string, roleNames []string, schemaRulesJson string, opts *Options) {
 	fmt.Println("📖 EXPLANATION")
 	fmt.Println("-------------")
 	fmt.Println("Ownership issues often occur when migrations are run by different users (e.g., 'deploy' vs 'postgres').")
@@ -227,12 +491,19 @@ func printExplanation(sqlQuery string, opts *Options) {
 
 	fmt.Println("🧠 INTERPRETATION")
 	fmt.Println("-----------------")
-	fmt.Println("• Expected: The user who SHOULD own all objects (usually the application user or migration user).")
+	fmt.Println("• Expected: The users who SHOULD own the objects (usually the application user or migration user).")
+	fmt.Println("• Member Of: Owners that are members of the given role (checked via pg_has_role) are accepted too.")
+	fmt.Println("• Schema Rules: Per-schema expected owners from pgok.toml replace the flags for that schema.")
 	fmt.Println("• Actual: The user who currently owns the object.")
+	fmt.Println("• Unknown roles: All expected roles must exist, otherwise the check stops with an error.")
+	fmt.Println("• Objects installed by extensions (functions, types) are skipped, they belong to the extension.")
+	fmt.Println("• Event Triggers and Publications are database-level objects, they are checked only with --schema='*'.")
 	fmt.Println("• Action: Run the generated REASSIGN/ALTER commands to fix ownership.")
 	fmt.Println("")
 
 	fmt.Println("💻 SQL QUERY")
 	fmt.Println("------------")
-	util.PrintRunnableSQL(sqlQuery, []interface{}{opts.Schema, opts.ExpectedOwner})
+	util.PrintRunnableSQL(unknownRolesSqlQuery, []interface{}{roleNames})
+	fmt.Println("")
+	util.PrintRunnableSQL(sqlQuery, []interface{}{opts.Schema, opts.ExpectedOwners, opts.ExpectedMemberOf, schemaRulesJson, opts.Exclude.Schemas, opts.Exclude.Tables})
 }
@@ -0,0 +1,287 @@
+package security_functions
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/pg-ok/pgok/internal/db"
+	"github.com/pg-ok/pgok/internal/util"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+type Options struct {
+	DbName            string
+	Schema            string
+	Exclude           util.Exclusion
+	IncludeExtensions bool
+	Explain           bool
+	Output            util.OutputFormat
+}
+
+func NewCommand() *cobra.Command {
+	opts := &Options{
+		// Default to scanning all schemas
+		Schema: "*",
+
+		Output: util.OutputFormatTable,
+	}
+
+	command := &cobra.Command{
+		GroupID: "security",
+
+		Use: "security:functions [db_name]",
+
+		Short: "Find insecure SECURITY DEFINER, superuser-owned and untrusted language functions",
+
+		Args: cobra.ExactArgs(1),
+
+		Run: func(cmd *cobra.Command, args []string) {
+			opts.DbName = args[0]
+			run(opts)
+		},
+	}
+
+	flags := command.Flags()
+	flags.StringVar(&opts.Schema, "schema", opts.Schema, "Schema name (use '*' for all user schemas)")
+	util.AddSchemaExclusionFlag(flags, &opts.Exclude)
+	flags.BoolVar(&opts.IncludeExtensions, "include-extensions", false, "Include functions installed by extensions")
+	flags.BoolVar(&opts.Explain, "explain", false, "Print the SQL query and explain the logic/interpretation")
+
+	flags.Var(&opts.Output, "output", "Output format (table, json)")
+	_ = command.RegisterFlagCompletionFunc("output", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{"table", "json"}, cobra.ShellCompDirectiveDefault
+	})
+
+	return command
+}
+
+const (
+	issueNoSearchPath      = "SECURITY DEFINER WITHOUT search_path"
+	issuePublicSuperuser   = "SUPERUSER-OWNED, PUBLIC EXECUTE"
+	issueUntrustedLanguage = "UNTRUSTED LANGUAGE"
+)
+
+type functionRow struct {
+	Schema                   string   `json:"schema"`
+	Function                 string   `json:"function"`
+	Arguments                string   `json:"arguments"`
+	Kind                     string   `json:"kind"`
+	Owner                    string   `json:"owner"`
+	Language                 string   `json:"language"`
+	IsSecurityDefiner        bool     `json:"is_security_definer"`
+	Config                   []string `json:"config"`
+	DefinerWithoutSearchPath bool     `json:"definer_without_search_path"`
+	SuperuserPublicExecute   bool     `json:"superuser_public_execute"`
+	IsUntrustedLanguage      bool     `json:"is_untrusted_language"`
+	IsExtensionMember        bool     `json:"is_extension_member"`
+	Issues                   []string `json:"issues"`
+}
+
+func run(opts *Options) {
+	manager := db.NewDbManager()
+
+	// proconfig holds per-function settings, e.g. {search_path=pg_catalog,pg_temp}.
+	// NULL proacl means the default ACL, which includes EXECUTE for PUBLIC.
+	rawSql := `
+       WITH functions AS (
+          SELECT
+             n.nspname AS schema_name,
+             p.proname AS function_name,
+             pg_get_function_identity_arguments(p.oid) AS arguments,
+             CASE p.prokind
+                WHEN 'p' THEN 'procedure'
+                WHEN 'a' THEN 'aggregate'
+                WHEN 'w' THEN 'window'
+                ELSE 'function'
+             END AS kind,
+             r.rolname AS owner_name,
+             l.lanname AS language_name,
+             p.prosecdef AS is_security_definer,
+             COALESCE(p.proconfig, '{}') AS config,
+             (
+                p.prosecdef
+                AND NOT EXISTS (
+                   SELECT 1 FROM UNNEST(p.proconfig) AS cfg WHERE cfg LIKE 'search_path=%'
+                )
+             ) AS definer_without_search_path,
+             (
+                r.rolsuper
+                AND EXISTS (
+                   SELECT 1
+                   FROM aclexplode(COALESCE(p.proacl, acldefault('f', p.proowner))) AS a
+                   WHERE a.grantee = 0 AND a.privilege_type = 'EXECUTE'
+                )
+             ) AS superuser_public_execute,
+             NOT l.lanpltrusted AS is_untrusted_language,
+             EXISTS (
+                SELECT 1
+                FROM pg_depend AS d
+                WHERE d.classid = 'pg_proc'::REGCLASS
+                  AND d.objid = p.oid
+                  AND d.deptype = 'e'
+             ) AS is_extension_member
+          FROM pg_proc AS p
+          JOIN pg_namespace AS n ON n.oid = p.pronamespace
+          JOIN pg_language AS l ON l.oid = p.prolang
+          JOIN pg_roles AS r ON r.oid = p.proowner
+          WHERE
+             ($1 = '*' OR n.nspname = $1)
+             AND n.nspname NOT IN ('pg_catalog', 'information_schema')
+             AND n.nspname NOT LIKE 'pg_toast%'
+             AND NOT (n.nspname = ANY($3::TEXT[]))
+       )
+       SELECT *
+       FROM functions
+       WHERE
+          (definer_without_search_path OR superuser_public_execute OR is_untrusted_language)
+          AND ($2 OR NOT is_extension_member)
+       ORDER BY schema_name, function_name, arguments;
+    `
+
+	sqlQuery := util.TrimLeftSpaces(rawSql)
+
+	if opts.Explain {
+		printExplanation(sqlQuery, opts)
+		return
+	}
+
+	ctx := context.Background()
+	conn, err := manager.Connect(ctx, opts.DbName)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error connecting to database: %v\n", err)
+		os.Exit(1)
+	}
+	defer func(conn *pgx.Conn, ctx context.Context) {
+		err := conn.Close(ctx)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error closing connection: %v\n", err)
+		}
+	}(conn, ctx)
+
+	rows, err := conn.Query(ctx, sqlQuery, opts.Schema, opts.IncludeExtensions, opts.Exclude.Schemas)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Query failed: %v\n", err)
+		os.Exit(1)
+	}
+	defer rows.Close()
+
+	results := []functionRow{}
+
+	for rows.Next() {
+		var r functionRow
+
+		err := rows.Scan(
+			&r.Schema,
+			&r.Function,
+			&r.Arguments,
+			&r.Kind,
+			&r.Owner,
+			&r.Language,
+			&r.IsSecurityDefiner,
+			&r.Config,
+			&r.DefinerWithoutSearchPath,
+			&r.SuperuserPublicExecute,
+			&r.IsUntrustedLanguage,
+			&r.IsExtensionMember,
+		)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Row scan failed: %v\n", err)
+			os.Exit(1)
+		}
+
+		if r.DefinerWithoutSearchPath {
+			r.Issues = append(r.Issues, issueNoSearchPath)
+		}
+		if r.SuperuserPublicExecute {
+			r.Issues = append(r.Issues, issuePublicSuperuser)
+		}
+		if r.IsUntrustedLanguage {
+			r.Issues = append(r.Issues, issueUntrustedLanguage)
+		}
+
+		results = append(results, r)
+	}
+
+	if rows.Err() != nil {
+		fmt.Fprintf(os.Stderr, "Rows iteration failed: %v\n", rows.Err())
+		os.Exit(1)
+	}
+
+	switch opts.Output {
+	case util.OutputFormatJson:
+		jsonData, _ := json.MarshalIndent(results, "", "  ")
+		fmt.Println(string(jsonData))
+
+	default:
+		schemaDisplay := opts.Schema
+		if opts.Schema == "*" {
+			schemaDisplay = "ALL (except system)"
+		}
+
+		fmt.Printf("Searching for insecure functions in `%s`\n", opts.DbName)
+		fmt.Printf("Schema: %s, Include Extensions: %t\n", schemaDisplay, opts.IncludeExtensions)
+
+		if len(results) == 0 {
+			fmt.Println(strings.Repeat("-", 80))
+			fmt.Println("No insecure functions found. 🔒")
+			return
+		}
+
+		table := tablewriter.NewWriter(os.Stdout)
+		table.Header([]string{"Schema", "Function", "Kind", "Owner", "Language", "Issues"})
+
+		for _, row := range results {
+			functionDisplay := fmt.Sprintf("%s(%s)", row.Function, row.Arguments)
+			if row.IsExtensionMember {
+				functionDisplay += " [EXT]"
+			}
+
+			err := table.Append([]string{
+				row.Schema,
+				functionDisplay,
+				row.Kind,
+				row.Owner,
+				row.Language,
+				strings.Join(row.Issues, ", "),
+			})
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error appending table row: %v\n", err)
+			}
+		}
+		if err := table.Render(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error rendering table: %v\n", err)
+		}
+
+		fmt.Println(strings.Repeat("-", 80))
+		fmt.Println("* Pin search_path: ALTER FUNCTION schema.name(args) SET search_path = pg_catalog, pg_temp;")
+		fmt.Println("* Restrict execution: REVOKE EXECUTE ON FUNCTION schema.name(args) FROM PUBLIC;")
+		fmt.Println("* [EXT] = Installed by an extension (shown with --include-extensions).")
+	}
+}
+
+func printExplanation(sqlQuery string, opts *Options) {
+	fmt.Println("📖 EXPLANATION")
+	fmt.Println("-------------")
+	fmt.Println("Functions run with the privileges of the caller, except SECURITY DEFINER functions,")
+	fmt.Println("which run with the privileges of their owner, like setuid programs.")
+	fmt.Println("")
+
+	fmt.Println("🧠 INTERPRETATION")
+	fmt.Println("-----------------")
+	fmt.Println("• SECURITY DEFINER WITHOUT search_path: A caller can create objects in a schema earlier in search_path")
+	fmt.Println("  (e.g. pg_temp or public) and make the function call them with the owner's privileges.")
+	fmt.Println("• SUPERUSER-OWNED, PUBLIC EXECUTE: Any role can call it. Critical when combined with SECURITY DEFINER.")
+	fmt.Println("• UNTRUSTED LANGUAGE: Languages like plpython3u or C can access the file system and the server process.")
+	fmt.Println("• Extension functions are skipped by default, they are maintained by the extension authors.")
+	fmt.Println("")
+
+	fmt.Println("💻 SQL QUERY")
+	fmt.Println("------------")
+	util.PrintRunnableSQL(sqlQuery, []interface{}{opts.Schema, opts.IncludeExtensions, opts.Exclude.Schemas})
+}
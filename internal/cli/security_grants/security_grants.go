@@ -0,0 +1,370 @@
+package security_grants
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/pg-ok/pgok/internal/db"
+	"github.com/pg-ok/pgok/internal/util"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+type Options struct {
+	DbName           string
+	Schema           string
+	Exclude          util.Exclusion
+	IncludeFunctions bool
+	Explain          bool
+	Output           util.OutputFormat
+}
+
+func NewCommand() *cobra.Command {
+	opts := &Options{
+		// Default to scanning all schemas
+		Schema: "*",
+
+		Output: util.OutputFormatTable,
+	}
+
+	command := &cobra.Command{
+		GroupID: "security",
+
+		Use: "security:grants [db_name]",
+
+		Short: "Find privileges granted to PUBLIC and write grants to non-owners",
+
+		Long: `Inspect ACLs of tables, schemas, functions and default privileges.
+Reports privileges granted to PUBLIC (including CREATE on schemas)
+and write privileges (INSERT, UPDATE, DELETE, TRUNCATE) granted to roles that do not own the table.`,
+
+		Args: cobra.ExactArgs(1),
+
+		Run: func(cmd *cobra.Command, args []string) {
+			opts.DbName = args[0]
+			run(opts)
+		},
+	}
+
+	flags := command.Flags()
+	flags.StringVar(&opts.Schema, "schema", opts.Schema, "Schema name (use '*' for all user schemas)")
+	util.AddExclusionFlags(flags, &opts.Exclude)
+	flags.BoolVar(&opts.IncludeFunctions, "include-functions", false, "Also report EXECUTE for PUBLIC on functions (granted by default)")
+	flags.BoolVar(&opts.Explain, "explain", false, "Print the SQL query and explain the logic/interpretation")
+
+	flags.Var(&opts.Output, "output", "Output format (table, json)")
+	_ = command.RegisterFlagCompletionFunc("output", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{"table", "json"}, cobra.ShellCompDirectiveDefault
+	})
+
+	return command
+}
+
+type grantEntry struct {
+	Grantee     string `json:"grantee"`
+	Grantor     string `json:"grantor"`
+	Privilege   string `json:"privilege"`
+	IsGrantable bool   `json:"is_grantable"`
+	Issue       string `json:"issue"`
+}
+
+type grantObjectRow struct {
+	ObjectType string       `json:"object_type"`
+	Schema     string       `json:"schema"`
+	Object     string       `json:"object"`
+	Owner      string       `json:"owner"`
+	Issues     []string     `json:"issues"`
+	Grants     []grantEntry `json:"grants"`
+}
+
+func run(opts *Options) {
+	manager := db.NewDbManager()
+
+	// NULL ACLs mean built-in defaults, acldefault() expands them so they are checked the same way.
+	// defaclnamespace = 0 means default privileges for all schemas.
+	rawSql := `
+       WITH acl AS (
+          SELECT
+             CASE c.relkind
+                WHEN 'r' THEN 'table'
+                WHEN 'p' THEN 'partitioned table'
+                WHEN 'v' THEN 'view'
+                WHEN 'm' THEN 'materialized view'
+                WHEN 'f' THEN 'foreign table'
+                WHEN 'S' THEN 'sequence'
+             END AS object_type,
+             n.nspname AS schema_name,
+             c.relname AS object_name,
+             c.relowner AS owner_oid,
+             a.grantor,
+             a.grantee,
+             a.privilege_type,
+             a.is_grantable
+          FROM pg_class AS c
+          JOIN pg_namespace AS n ON n.oid = c.relnamespace
+          CROSS JOIN LATERAL aclexplode(
+             COALESCE(c.relacl, acldefault(CASE WHEN c.relkind = 'S' THEN 's' ELSE 'r' END::"char", c.relowner))
+          ) AS a
+          WHERE c.relkind IN ('r', 'p', 'v', 'm', 'f', 'S')
+
+          UNION ALL
+
+          SELECT
+             'schema',
+             n.nspname,
+             n.nspname,
+             n.nspowner,
+             a.grantor,
+             a.grantee,
+             a.privilege_type,
+             a.is_grantable
+          FROM pg_namespace AS n
+          CROSS JOIN LATERAL aclexplode(COALESCE(n.nspacl, acldefault('n', n.nspowner))) AS a
+
+          UNION ALL
+
+          SELECT
+             CASE p.prokind WHEN 'p' THEN 'procedure' ELSE 'function' END,
+             n.nspname,
+             p.proname || '(' || pg_get_function_identity_arguments(p.oid) || ')',
+             p.proowner,
+             a.grantor,
+             a.grantee,
+             a.privilege_type,
+             a.is_grantable
+          FROM pg_proc AS p
+          JOIN pg_namespace AS n ON n.oid = p.pronamespace
+          CROSS JOIN LATERAL aclexplode(COALESCE(p.proacl, acldefault('f', p.proowner))) AS a
+          WHERE $2
+
+          UNION ALL
+
+          SELECT
+             'default privileges',
+             COALESCE(n.nspname, '*'),
+             'FOR ROLE ' || pg_get_userbyid(d.defaclrole) || ' ON ' ||
+             CASE d.defaclobjtype
+                WHEN 'r' THEN 'TABLES'
+                WHEN 'S' THEN 'SEQUENCES'
+                WHEN 'f' THEN 'FUNCTIONS'
+                WHEN 'T' THEN 'TYPES'
+                WHEN 'n' THEN 'SCHEMAS'
+             END,
+             d.defaclrole,
+             a.grantor,
+             a.grantee,
+             a.privilege_type,
+             a.is_grantable
+          FROM pg_default_acl AS d
+          LEFT JOIN pg_namespace AS n ON n.oid = d.defaclnamespace
+          CROSS JOIN LATERAL aclexplode(d.defaclacl) AS a
+       )
+       SELECT
+          object_type,
+          schema_name,
+          object_name,
+          pg_get_userbyid(owner_oid) AS owner_name,
+          CASE WHEN grantee = 0 THEN 'PUBLIC' ELSE pg_get_userbyid(grantee) END AS grantee_name,
+          pg_get_userbyid(grantor) AS grantor_name,
+          privilege_type,
+          is_grantable,
+          CASE
+             WHEN grantee = 0 AND object_type = 'schema' AND privilege_type = 'CREATE' THEN 'PUBLIC CREATE'
+             WHEN grantee = 0 THEN 'PUBLIC'
+             ELSE 'NON-OWNER WRITE'
+          END AS issue
+       FROM acl
+       WHERE
+          (
+             grantee = 0
+             OR (
+                grantee <> owner_oid
+                AND object_type IN ('table', 'partitioned table', 'foreign table', 'default privileges')
+                AND privilege_type IN ('INSERT', 'UPDATE', 'DELETE', 'TRUNCATE')
+             )
+          )
+          AND ($1 = '*' OR schema_name = $1 OR schema_name = '*')
+          AND schema_name NOT IN ('pg_catalog', 'information_schema')
+          AND schema_name NOT LIKE 'pg_toast%'
+          AND schema_name NOT LIKE 'pg_temp%'
+          AND NOT (schema_name = ANY($3::TEXT[]))
+          AND NOT (object_name = ANY($4::TEXT[]) OR schema_name || '.' || object_name = ANY($4::TEXT[]))
+       ORDER BY schema_name, object_type, object_name, grantee_name, privilege_type;
+    `
+
+	sqlQuery := util.TrimLeftSpaces(rawSql)
+
+	if opts.Explain {
+		printExplanation(sqlQuery, opts)
+		return
+	}
+
+	ctx := context.Background()
+	conn, err := manager.Connect(ctx, opts.DbName)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error connecting to database: %v\n", err)
+		os.Exit(1)
+	}
+	defer func(conn *pgx.Conn, ctx context.Context) {
+		err := conn.Close(ctx)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error closing connection: %v\n", err)
+		}
+	}(conn, ctx)
+
+	rows, err := conn.Query(ctx, sqlQuery, opts.Schema, opts.IncludeFunctions, opts.Exclude.Schemas, opts.Exclude.Tables)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Query failed: %v\n", err)
+		os.Exit(1)
+	}
+	defer rows.Close()
+
+	// Rows are ordered by object, so grants of the same object are adjacent
+	results := []grantObjectRow{}
+
+	for rows.Next() {
+		var obj grantObjectRow
+		var g grantEntry
+
+		err := rows.Scan(
+			&obj.ObjectType,
+			&obj.Schema,
+			&obj.Object,
+			&obj.Owner,
+			&g.Grantee,
+			&g.Grantor,
+			&g.Privilege,
+			&g.IsGrantable,
+			&g.Issue,
+		)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Row scan failed: %v\n", err)
+			os.Exit(1)
+		}
+
+		last := len(results) - 1
+		if last < 0 || results[last].ObjectType != obj.ObjectType || results[last].Schema != obj.Schema || results[last].Object != obj.Object {
+			results = append(results, obj)
+			last++
+		}
+
+		results[last].Grants = append(results[last].Grants, g)
+		if !containsString(results[last].Issues, g.Issue) {
+			results[last].Issues = append(results[last].Issues, g.Issue)
+		}
+	}
+
+	if rows.Err() != nil {
+		fmt.Fprintf(os.Stderr, "Rows iteration failed: %v\n", rows.Err())
+		os.Exit(1)
+	}
+
+	switch opts.Output {
+	case util.OutputFormatJson:
+		jsonData, _ := json.MarshalIndent(results, "", "  ")
+		fmt.Println(string(jsonData))
+
+	default:
+		schemaDisplay := opts.Schema
+		if opts.Schema == "*" {
+			schemaDisplay = "ALL (except system)"
+		}
+
+		fmt.Printf("Searching for risky grants in `%s`\n", opts.DbName)
+		fmt.Printf("Schema: %s, Include Functions: %t\n", schemaDisplay, opts.IncludeFunctions)
+
+		if len(results) == 0 {
+			fmt.Println(strings.Repeat("-", 80))
+			fmt.Println("No risky grants found. 🔒")
+			return
+		}
+
+		table := tablewriter.NewWriter(os.Stdout)
+		table.Header([]string{"Schema", "Type", "Object", "Owner", "Issues", "Grants"})
+
+		for _, row := range results {
+			err := table.Append([]string{
+				row.Schema,
+				row.ObjectType,
+				row.Object,
+				row.Owner,
+				strings.Join(row.Issues, ", "),
+				formatGrants(row.Grants),
+			})
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error appending table row: %v\n", err)
+			}
+		}
+		if err := table.Render(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error rendering table: %v\n", err)
+		}
+
+		fmt.Println(strings.Repeat("-", 80))
+		fmt.Println("* PUBLIC = every role, including ones created in the future.")
+		fmt.Println("* Fix: REVOKE ... FROM PUBLIC; REVOKE CREATE ON SCHEMA public FROM PUBLIC;")
+		fmt.Println("* Schema '*' = default privileges for all schemas.")
+	}
+}
+
+// formatGrants groups privileges by grantee, e.g. "PUBLIC: SELECT, INSERT; app: UPDATE".
+// Grantable privileges are marked with "*", as in psql.
+func formatGrants(grants []grantEntry) string {
+	var grantees []string
+	privileges := make(map[string][]string)
+
+	for _, g := range grants {
+		if _, ok := privileges[g.Grantee]; !ok {
+			grantees = append(grantees, g.Grantee)
+		}
+
+		privilege := g.Privilege
+		if g.IsGrantable {
+			privilege += "*"
+		}
+		privileges[g.Grantee] = append(privileges[g.Grantee], privilege)
+	}
+
+	var parts []string
+	for _, grantee := range grantees {
+		parts = append(parts, grantee+": "+strings.Join(privileges[grantee], ", "))
+	}
+
+	return strings.Join(parts, "; ")
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func printExplanation(sqlQuery string, opts *Options) {
+	fmt.Println("📖 EXPLANATION")
+	fmt.Println("-------------")
+	fmt.Println("Privileges are stored as ACL arrays (aclitem[]) in relacl, nspacl, proacl and pg_default_acl.")
+	fmt.Println("aclexplode() decodes them into grantee/privilege pairs. NULL ACLs are expanded with acldefault().")
+	fmt.Println("")
+
+	fmt.Println("🧠 INTERPRETATION")
+	fmt.Println("-----------------")
+	fmt.Println("• PUBLIC: The privilege is granted to every role, including roles created in the future.")
+	fmt.Println("• PUBLIC CREATE: Anyone can create objects in the schema (default for 'public' before PG15).")
+	fmt.Println("  Combined with search_path this allows to hijack function calls of other roles.")
+	fmt.Println("• NON-OWNER WRITE: INSERT, UPDATE, DELETE or TRUNCATE granted to a role that does not own the object.")
+	fmt.Println("  Verify it is intended (e.g. application role) and not a leftover of a migration.")
+	fmt.Println("• default privileges: Grants that will be applied automatically to objects created in the future.")
+	fmt.Println("• Functions: EXECUTE for PUBLIC is the PostgreSQL default, so functions are checked only with --include-functions.")
+	fmt.Println("")
+
+	fmt.Println("💻 SQL QUERY")
+	fmt.Println("------------")
+	util.PrintRunnableSQL(sqlQuery, []interface{}{opts.Schema, opts.IncludeFunctions, opts.Exclude.Schemas, opts.Exclude.Tables})
+}
@@ -0,0 +1,428 @@
+package security_rls
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/pg-ok/pgok/internal/db"
+	"github.com/pg-ok/pgok/internal/util"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+type Options struct {
+	DbName       string
+	Schema       string
+	Exclude      util.Exclusion
+	TenantColumn string
+	Explain      bool
+	Output       util.OutputFormat
+}
+
+func NewCommand() *cobra.Command {
+	opts := &Options{
+		// Default to scanning all schemas
+		Schema: "*",
+
+		TenantColumn: "^(tenant|organization|org|account|customer)_id$",
+
+		Output: util.OutputFormatTable,
+	}
+
+	command := &cobra.Command{
+		GroupID: "security",
+
+		Use: "security:rls [db_name]",
+
+		Short: "Check Row Level Security of multi-tenant tables",
+
+		Long: `Check Row Level Security (RLS) of tables with a tenant column (matched by --tenant-column regex)
+and of tables with RLS enabled. Lists the policies of each table.`,
+
+		Args: cobra.ExactArgs(1),
+
+		Run: func(cmd *cobra.Command, args []string) {
+			opts.DbName = args[0]
+			run(opts)
+		},
+	}
+
+	flags := command.Flags()
+	flags.StringVar(&opts.Schema, "schema", opts.Schema, "Schema name (use '*' for all user schemas)")
+	util.AddExclusionFlags(flags, &opts.Exclude)
+	flags.StringVar(&opts.TenantColumn, "tenant-column", opts.TenantColumn, "Regex (PostgreSQL syntax) matching tenant column names")
+	flags.BoolVar(&opts.Explain, "explain", false, "Print the SQL query and explain the logic/interpretation")
+
+	flags.Var(&opts.Output, "output", "Output format (table, json)")
+	_ = command.RegisterFlagCompletionFunc("output", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{"table", "json"}, cobra.ShellCompDirectiveDefault
+	})
+
+	return command
+}
+
+const (
+	issueRlsDisabled = "RLS DISABLED"
+	issueNoPolicies  = "NO POLICIES"
+	issueForceOff    = "FORCE RLS OFF"
+)
+
+type rlsTableRow struct {
+	Schema           string      `json:"schema"`
+	Table            string      `json:"table"`
+	Owner            string      `json:"owner"`
+	OwnerCanLogin    bool        `json:"owner_can_login"`
+	OwnerIsSuperuser bool        `json:"owner_is_superuser"`
+	TenantColumns    []string    `json:"tenant_columns"`
+	RlsEnabled       bool        `json:"rls_enabled"`
+	RlsForced        bool        `json:"rls_forced"`
+	PoliciesCount    int64       `json:"policies_count"`
+	Issues           []string    `json:"issues"`
+	Policies         []policyRow `json:"policies"`
+}
+
+type policyRow struct {
+	Schema     string   `json:"-"`
+	Table      string   `json:"-"`
+	Policy     string   `json:"policy"`
+	Permissive bool     `json:"permissive"`
+	Command    string   `json:"command"`
+	Roles      []string `json:"roles"`
+	Using      *string  `json:"using"`      // Pointer to handle NULL
+	WithCheck  *string  `json:"with_check"` // Pointer to handle NULL
+}
+
+func run(opts *Options) {
+	manager := db.NewDbManager()
+
+	// Partitions are skipped: RLS of the partitioned parent applies when queried through it
+	rawTablesSql := `
+       SELECT
+          n.nspname AS schema_name,
+          c.relname AS table_name,
+          r.rolname AS owner_name,
+          r.rolcanlogin AS owner_can_login,
+          r.rolsuper AS owner_is_superuser,
+          ARRAY(
+             SELECT a.attname::TEXT
+             FROM pg_attribute AS a
+             WHERE a.attrelid = c.oid
+               AND a.attnum > 0
+               AND NOT a.attisdropped
+               AND a.attname ~ $2
+             ORDER BY a.attnum
+          ) AS tenant_columns,
+          c.relrowsecurity AS rls_enabled,
+          c.relforcerowsecurity AS rls_forced,
+          (SELECT COUNT(*) FROM pg_policy AS pol WHERE pol.polrelid = c.oid) AS policies_count
+       FROM pg_class AS c
+       JOIN pg_namespace AS n ON n.oid = c.relnamespace
+       JOIN pg_roles AS r ON r.oid = c.relowner
+       WHERE
+          c.relkind IN ('r', 'p')
+          AND NOT c.relispartition
+          AND ($1 = '*' OR n.nspname = $1)
+          AND n.nspname NOT IN ('pg_catalog', 'information_schema')
+          AND n.nspname NOT LIKE 'pg_toast%'
+          AND NOT (n.nspname = ANY($3::TEXT[]))
+          AND NOT (c.relname = ANY($4::TEXT[]) OR n.nspname || '.' || c.relname = ANY($4::TEXT[]))
+          AND (
+             c.relrowsecurity
+             OR EXISTS (
+                SELECT 1
+                FROM pg_attribute AS a
+                WHERE a.attrelid = c.oid
+                  AND a.attnum > 0
+                  AND NOT a.attisdropped
+                  AND a.attname ~ $2
+             )
+          )
+       ORDER BY schema_name, table_name;
+    `
+
+	// polroles = {0} means PUBLIC
+	rawPoliciesSql := `
+       SELECT
+          n.nspname AS schema_name,
+          c.relname AS table_name,
+          pol.polname AS policy_name,
+          pol.polpermissive AS permissive,
+          CASE pol.polcmd
+             WHEN 'r' THEN 'SELECT'
+             WHEN 'a' THEN 'INSERT'
+             WHEN 'w' THEN 'UPDATE'
+             WHEN 'd' THEN 'DELETE'
+             ELSE 'ALL'
+          END AS command,
+          ARRAY(
+             SELECT CASE WHEN role_oid = 0 THEN 'PUBLIC' ELSE pg_get_userbyid(role_oid)::TEXT END
+             FROM UNNEST(pol.polroles) AS role_oid
+          ) AS roles,
+          pg_get_expr(pol.polqual, pol.polrelid) AS using_expression,
+          pg_get_expr(pol.polwithcheck, pol.polrelid) AS with_check_expression
+       FROM pg_policy AS pol
+       JOIN pg_class AS c ON c.oid = pol.polrelid
+       JOIN pg_namespace AS n ON n.oid = c.relnamespace
+       WHERE
+          ($1 = '*' OR n.nspname = $1)
+          AND n.nspname NOT IN ('pg_catalog', 'information_schema')
+          AND NOT (n.nspname = ANY($2::TEXT[]))
+          AND NOT (c.relname = ANY($3::TEXT[]) OR n.nspname || '.' || c.relname = ANY($3::TEXT[]))
+       ORDER BY schema_name, table_name, policy_name;
+    `
+
+	tablesSqlQuery := util.TrimLeftSpaces(rawTablesSql)
+	policiesSqlQuery := util.TrimLeftSpaces(rawPoliciesSql)
+
+	if opts.Explain {
+		printExplanation(tablesSqlQuery, policiesSqlQuery, opts)
+		return
+	}
+
+	ctx := context.Background()
+	conn, err := manager.Connect(ctx, opts.DbName)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error connecting to database: %v\n", err)
+		os.Exit(1)
+	}
+	defer func(conn *pgx.Conn, ctx context.Context) {
+		err := conn.Close(ctx)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error closing connection: %v\n", err)
+		}
+	}(conn, ctx)
+
+	tables := queryTables(ctx, conn, tablesSqlQuery, opts)
+	policies := queryPolicies(ctx, conn, policiesSqlQuery, opts)
+
+	tableIndex := make(map[string]int)
+	for i := range tables {
+		tableIndex[tables[i].Schema+"."+tables[i].Table] = i
+	}
+
+	policiesCount := 0
+	for _, policy := range policies {
+		if i, ok := tableIndex[policy.Schema+"."+policy.Table]; ok {
+			tables[i].Policies = append(tables[i].Policies, policy)
+			policiesCount++
+		}
+	}
+
+	switch opts.Output {
+	case util.OutputFormatJson:
+		jsonData, _ := json.MarshalIndent(tables, "", "  ")
+		fmt.Println(string(jsonData))
+
+	default:
+		schemaDisplay := opts.Schema
+		if opts.Schema == "*" {
+			schemaDisplay = "ALL (except system)"
+		}
+
+		fmt.Printf("Checking Row Level Security in `%s`\n", opts.DbName)
+		fmt.Printf("Schema: %s, Tenant Column: %s\n", schemaDisplay, opts.TenantColumn)
+
+		if len(tables) == 0 {
+			fmt.Println(strings.Repeat("-", 80))
+			fmt.Println("No tables with tenant columns or RLS found.")
+			return
+		}
+
+		table := tablewriter.NewWriter(os.Stdout)
+		table.Header([]string{"Schema", "Table", "Tenant Columns", "RLS", "Force", "Policies", "Owner", "Issues"})
+
+		for _, row := range tables {
+			issuesDisplay := strings.Join(row.Issues, ", ")
+			if issuesDisplay == "" {
+				issuesDisplay = "OK"
+			}
+
+			err := table.Append([]string{
+				row.Schema,
+				row.Table,
+				strings.Join(row.TenantColumns, ", "),
+				formatBool(row.RlsEnabled),
+				formatBool(row.RlsForced),
+				fmt.Sprintf("%d", row.PoliciesCount),
+				row.Owner,
+				issuesDisplay,
+			})
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error appending table row: %v\n", err)
+			}
+		}
+		if err := table.Render(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error rendering table: %v\n", err)
+		}
+
+		if policiesCount > 0 {
+			fmt.Println("\nPolicies:")
+
+			policiesTable := tablewriter.NewWriter(os.Stdout)
+			policiesTable.Header([]string{"Schema", "Table", "Policy", "Command", "Roles", "Using", "With Check"})
+
+			for _, row := range tables {
+				for _, policy := range row.Policies {
+					policyDisplay := policy.Policy
+					if !policy.Permissive {
+						policyDisplay += " [RESTRICTIVE]"
+					}
+
+					err := policiesTable.Append([]string{
+						row.Schema,
+						row.Table,
+						policyDisplay,
+						policy.Command,
+						strings.Join(policy.Roles, ", "),
+						formatExpression(policy.Using),
+						formatExpression(policy.WithCheck),
+					})
+					if err != nil {
+						fmt.Fprintf(os.Stderr, "Error appending table row: %v\n", err)
+					}
+				}
+			}
+			if err := policiesTable.Render(); err != nil {
+				fmt.Fprintf(os.Stderr, "Error rendering table: %v\n", err)
+			}
+		}
+
+		fmt.Println(strings.Repeat("-", 80))
+		fmt.Println("* RLS DISABLED: ALTER TABLE ... ENABLE ROW LEVEL SECURITY; (and create policies first).")
+		fmt.Println("* NO POLICIES: RLS is enabled, but all rows are hidden from everyone except the owner and BYPASSRLS roles.")
+		fmt.Println("* FORCE RLS OFF: The owner is a login role and bypasses policies. ALTER TABLE ... FORCE ROW LEVEL SECURITY;")
+	}
+}
+
+func queryTables(ctx context.Context, conn *pgx.Conn, sqlQuery string, opts *Options) []rlsTableRow {
+	rows, err := conn.Query(ctx, sqlQuery, opts.Schema, opts.TenantColumn, opts.Exclude.Schemas, opts.Exclude.Tables)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Query failed: %v\n", err)
+		os.Exit(1)
+	}
+	defer rows.Close()
+
+	results := []rlsTableRow{}
+
+	for rows.Next() {
+		var r rlsTableRow
+
+		err := rows.Scan(
+			&r.Schema,
+			&r.Table,
+			&r.Owner,
+			&r.OwnerCanLogin,
+			&r.OwnerIsSuperuser,
+			&r.TenantColumns,
+			&r.RlsEnabled,
+			&r.RlsForced,
+			&r.PoliciesCount,
+		)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Row scan failed: %v\n", err)
+			os.Exit(1)
+		}
+
+		if len(r.TenantColumns) > 0 && !r.RlsEnabled {
+			r.Issues = append(r.Issues, issueRlsDisabled)
+		}
+		if r.RlsEnabled && r.PoliciesCount == 0 {
+			r.Issues = append(r.Issues, issueNoPolicies)
+		}
+		// Superusers bypass RLS anyway, so forcing it only matters for application owners
+		if r.RlsEnabled && !r.RlsForced && r.OwnerCanLogin && !r.OwnerIsSuperuser {
+			r.Issues = append(r.Issues, issueForceOff)
+		}
+
+		results = append(results, r)
+	}
+
+	if rows.Err() != nil {
+		fmt.Fprintf(os.Stderr, "Rows iteration failed: %v\n", rows.Err())
+		os.Exit(1)
+	}
+
+	return results
+}
+
+func queryPolicies(ctx context.Context, conn *pgx.Conn, sqlQuery string, opts *Options) []policyRow {
+	rows, err := conn.Query(ctx, sqlQuery, opts.Schema, opts.Exclude.Schemas, opts.Exclude.Tables)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Query failed: %v\n", err)
+		os.Exit(1)
+	}
+	defer rows.Close()
+
+	var results []policyRow
+
+	for rows.Next() {
+		var r policyRow
+
+		err := rows.Scan(
+			&r.Schema,
+			&r.Table,
+			&r.Policy,
+			&r.Permissive,
+			&r.Command,
+			&r.Roles,
+			&r.Using,
+			&r.WithCheck,
+		)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Row scan failed: %v\n", err)
+			os.Exit(1)
+		}
+
+		results = append(results, r)
+	}
+
+	if rows.Err() != nil {
+		fmt.Fprintf(os.Stderr, "Rows iteration failed: %v\n", rows.Err())
+		os.Exit(1)
+	}
+
+	return results
+}
+
+func formatBool(value bool) string {
+	if value {
+		return "on"
+	}
+	return "off"
+}
+
+func formatExpression(expression *string) string {
+	if expression == nil {
+		return "-"
+	}
+	return *expression
+}
+
+func printExplanation(tablesSqlQuery string, policiesSqlQuery string, opts *Options) {
+	fmt.Println("📖 EXPLANATION")
+	fmt.Println("-------------")
+	fmt.Println("Row Level Security (RLS) filters rows by policies, e.g. USING (tenant_id = current_setting('app.tenant_id')::INT).")
+	fmt.Println("In a multi-tenant schema a single table without RLS can leak data of all tenants.")
+	fmt.Println("Tables are selected by a tenant column (--tenant-column regex) or by RLS being enabled.")
+	fmt.Println("")
+
+	fmt.Println("🧠 INTERPRETATION")
+	fmt.Println("-----------------")
+	fmt.Println("• RLS DISABLED: The table has a tenant column, but rows are not filtered.")
+	fmt.Println("• NO POLICIES: RLS is enabled, but no policy exists, so non-owners see no rows (default deny).")
+	fmt.Println("• FORCE RLS OFF: Table owners bypass RLS unless FORCE ROW LEVEL SECURITY is set.")
+	fmt.Println("  Reported when the owner is a login (application) role that is not a superuser.")
+	fmt.Println("• Policies: [RESTRICTIVE] policies are combined with AND, permissive ones with OR.")
+	fmt.Println("")
+
+	fmt.Println("💻 SQL QUERY")
+	fmt.Println("------------")
+	util.PrintRunnableSQL(tablesSqlQuery, []interface{}{opts.Schema, opts.TenantColumn, opts.Exclude.Schemas, opts.Exclude.Tables})
+	fmt.Println("")
+	util.PrintRunnableSQL(policiesSqlQuery, []interface{}{opts.Schema, opts.Exclude.Schemas, opts.Exclude.Tables})
+}
@@ -0,0 +1,372 @@
+package security_roles
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/pg-ok/pgok/internal/db"
+	"github.com/pg-ok/pgok/internal/util"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+type Options struct {
+	DbName  string
+	Explain bool
+	Output  util.OutputFormat
+}
+
+func NewCommand() *cobra.Command {
+	opts := &Options{
+		Output: util.OutputFormatTable,
+	}
+
+	command := &cobra.Command{
+		GroupID: "security",
+
+		Use: "security:roles [db_name]",
+
+		Short: "Find privileged, weakly configured and unused roles",
+
+		Long: `Find roles with privileged attributes, login roles without password expiry,
+md5 passwords, unlimited connection limits and unused login roles.
+Password types require access to pg_authid (superuser), otherwise this check is skipped.`,
+
+		Args: cobra.ExactArgs(1),
+
+		Run: func(cmd *cobra.Command, args []string) {
+			opts.DbName = args[0]
+			run(opts)
+		},
+	}
+
+	flags := command.Flags()
+	flags.BoolVar(&opts.Explain, "explain", false, "Print the SQL query and explain the logic/interpretation")
+
+	flags.Var(&opts.Output, "output", "Output format (table, json)")
+	_ = command.RegisterFlagCompletionFunc("output", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{"table", "json"}, cobra.ShellCompDirectiveDefault
+	})
+
+	return command
+}
+
+const (
+	issuePrivileged   = "PRIVILEGED"
+	issueNoExpiry     = "NO PASSWORD EXPIRY"
+	issueMd5          = "MD5 PASSWORD"
+	issueUnlimited    = "UNLIMITED CONNECTIONS"
+	issueUnused       = "UNUSED"
+	passwordUnknown   = "unknown"
+	passwordNone      = "none"
+	passwordMd5       = "md5"
+	passwordScram     = "scram-sha-256"
+	insufficientPrivs = "42501"
+)
+
+type roleRow struct {
+	Role            string     `json:"role"`
+	CanLogin        bool       `json:"can_login"`
+	Attributes      []string   `json:"attributes"`
+	ValidUntil      *time.Time `json:"valid_until"` // NULL also for 'infinity' and '-infinity', see IsExpired
+	IsExpired       bool       `json:"is_expired"`
+	PasswordType    string     `json:"password_type"`
+	ConnectionLimit int64      `json:"connection_limit"`
+	HasSessions     bool       `json:"has_sessions"`
+	HasDependencies bool       `json:"has_dependencies"`
+	IsMember        bool       `json:"is_member"`
+	Issues          []string   `json:"issues"`
+
+	isSystem bool // Bootstrap superuser or the current user, never reported as unused
+}
+
+type rolesResult struct {
+	PasswordTypesAvailable bool      `json:"password_types_available"`
+	Roles                  []roleRow `json:"roles"`
+}
+
+func run(opts *Options) {
+	manager := db.NewDbManager()
+
+	// pg_shdepend covers owned objects and grants in ALL databases of the cluster,
+	// pg_stat_activity covers current sessions only
+	rawRolesSql := `
+       SELECT
+          r.rolname AS role_name,
+          r.rolcanlogin AS can_login,
+          ARRAY_REMOVE(ARRAY[
+             CASE WHEN r.rolsuper THEN 'SUPERUSER' END,
+             CASE WHEN r.rolcreaterole THEN 'CREATEROLE' END,
+             CASE WHEN r.rolcreatedb THEN 'CREATEDB' END,
+             CASE WHEN r.rolbypassrls THEN 'BYPASSRLS' END,
+             CASE WHEN r.rolreplication THEN 'REPLICATION' END
+          ], NULL) AS attributes,
+          CASE
+             WHEN r.rolvaliduntil IN ('infinity', '-infinity') THEN NULL
+             ELSE r.rolvaliduntil
+          END AS valid_until,
+          COALESCE(r.rolvaliduntil < now(), false) AS is_expired, -- '-infinity' = password never valid
+          r.rolconnlimit AS connection_limit,
+          EXISTS (
+             SELECT 1 FROM pg_stat_activity AS a WHERE a.usesysid = r.oid
+          ) AS has_sessions,
+          EXISTS (
+             SELECT 1
+             FROM pg_shdepend AS d
+             WHERE d.refclassid = 'pg_authid'::REGCLASS
+               AND d.refobjid = r.oid
+          ) AS has_dependencies,
+          EXISTS (
+             SELECT 1 FROM pg_auth_members AS m WHERE m.member = r.oid
+          ) AS is_member,
+          (r.oid = 10 OR r.rolname = current_user) AS is_system
+       FROM pg_roles AS r
+       WHERE r.rolname !~ '^pg_'
+       ORDER BY r.rolname;
+    `
+
+	// pg_authid is readable by superusers only
+	rawPasswordsSql := `
+       SELECT
+          rolname AS role_name,
+          CASE
+             WHEN rolpassword IS NULL THEN 'none'
+             WHEN rolpassword LIKE 'md5%' THEN 'md5'
+             WHEN rolpassword LIKE 'SCRAM-SHA-256$%' THEN 'scram-sha-256'
+             ELSE 'unknown'
+          END AS password_type
+       FROM pg_authid
+       WHERE rolname !~ '^pg_';
+    `
+
+	rolesSqlQuery := util.TrimLeftSpaces(rawRolesSql)
+	passwordsSqlQuery := util.TrimLeftSpaces(rawPasswordsSql)
+
+	if opts.Explain {
+		printExplanation(rolesSqlQuery, passwordsSqlQuery)
+		return
+	}
+
+	ctx := context.Background()
+	conn, err := manager.Connect(ctx, opts.DbName)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error connecting to database: %v\n", err)
+		os.Exit(1)
+	}
+	defer func(conn *pgx.Conn, ctx context.Context) {
+		err := conn.Close(ctx)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error closing connection: %v\n", err)
+		}
+	}(conn, ctx)
+
+	passwordTypes, passwordTypesAvailable := queryPasswordTypes(ctx, conn, passwordsSqlQuery)
+
+	roles := queryRoles(ctx, conn, rolesSqlQuery)
+
+	result := rolesResult{
+		PasswordTypesAvailable: passwordTypesAvailable,
+		Roles:                  []roleRow{},
+	}
+
+	for _, role := range roles {
+		role.PasswordType = passwordUnknown
+		if passwordTypesAvailable {
+			role.PasswordType = passwordTypes[role.Role]
+		}
+
+		if len(role.Attributes) > 0 {
+			role.Issues = append(role.Issues, issuePrivileged)
+		}
+
+		if role.CanLogin {
+			// Roles without a password (peer, cert auth) have nothing to expire.
+			// Without pg_authid it is unknown whether the role has a password, so nothing is reported.
+			if role.ValidUntil == nil && !role.IsExpired && passwordTypesAvailable && role.PasswordType != passwordNone {
+				role.Issues = append(role.Issues, issueNoExpiry)
+			}
+			if role.PasswordType == passwordMd5 {
+				role.Issues = append(role.Issues, issueMd5)
+			}
+			if role.ConnectionLimit == -1 {
+				role.Issues = append(role.Issues, issueUnlimited)
+			}
+			if !role.HasSessions && !role.HasDependencies && !role.IsMember && !role.isSystem {
+				role.Issues = append(role.Issues, issueUnused)
+			}
+		}
+
+		if len(role.Issues) > 0 {
+			result.Roles = append(result.Roles, role)
+		}
+	}
+
+	switch opts.Output {
+	case util.OutputFormatJson:
+		jsonData, _ := json.MarshalIndent(result, "", "  ")
+		fmt.Println(string(jsonData))
+
+	default:
+		fmt.Printf("Auditing roles in `%s`\n", opts.DbName)
+
+		if !passwordTypesAvailable {
+			fmt.Println("Warning: pg_authid is not readable (superuser required), password types and expiry are not checked.")
+		}
+
+		if len(result.Roles) == 0 {
+			fmt.Println(strings.Repeat("-", 80))
+			fmt.Println("No risky roles found. 🔒")
+			return
+		}
+
+		table := tablewriter.NewWriter(os.Stdout)
+		table.Header([]string{"Role", "Login", "Attributes", "Password", "Valid Until", "Conn Limit", "Issues"})
+
+		for _, row := range result.Roles {
+			loginDisplay := "no"
+			if row.CanLogin {
+				loginDisplay = "yes"
+			}
+
+			validUntilDisplay := "never expires"
+			switch {
+			case row.ValidUntil != nil && row.IsExpired:
+				validUntilDisplay = row.ValidUntil.Format("2006-01-02") + " (expired)"
+			case row.ValidUntil != nil:
+				validUntilDisplay = row.ValidUntil.Format("2006-01-02")
+			case row.IsExpired:
+				validUntilDisplay = "expired"
+			}
+
+			connLimitDisplay := fmt.Sprintf("%d", row.ConnectionLimit)
+			if row.ConnectionLimit == -1 {
+				connLimitDisplay = "unlimited"
+			}
+
+			err := table.Append([]string{
+				row.Role,
+				loginDisplay,
+				strings.Join(row.Attributes, ", "),
+				row.PasswordType,
+				validUntilDisplay,
+				connLimitDisplay,
+				strings.Join(row.Issues, ", "),
+			})
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error appending table row: %v\n", err)
+			}
+		}
+		if err := table.Render(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error rendering table: %v\n", err)
+		}
+
+		fmt.Println(strings.Repeat("-", 80))
+		fmt.Println("* UNUSED is a heuristic: login role with no sessions now, no owned objects or grants in any database and no role memberships.")
+		fmt.Println("* Migrate md5 passwords: SET password_encryption = 'scram-sha-256'; then reset the password (\\password in psql).")
+	}
+}
+
+func queryRoles(ctx context.Context, conn *pgx.Conn, sqlQuery string) []roleRow {
+	rows, err := conn.Query(ctx, sqlQuery)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Query failed: %v\n", err)
+		os.Exit(1)
+	}
+	defer rows.Close()
+
+	var results []roleRow
+
+	for rows.Next() {
+		var r roleRow
+
+		err := rows.Scan(
+			&r.Role,
+			&r.CanLogin,
+			&r.Attributes,
+			&r.ValidUntil,
+			&r.IsExpired,
+			&r.ConnectionLimit,
+			&r.HasSessions,
+			&r.HasDependencies,
+			&r.IsMember,
+			&r.isSystem,
+		)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Row scan failed: %v\n", err)
+			os.Exit(1)
+		}
+
+		results = append(results, r)
+	}
+
+	if rows.Err() != nil {
+		fmt.Fprintf(os.Stderr, "Rows iteration failed: %v\n", rows.Err())
+		os.Exit(1)
+	}
+
+	return results
+}
+
+// queryPasswordTypes returns password types by role name.
+// The second value is false when the current user is not allowed to read pg_authid.
+func queryPasswordTypes(ctx context.Context, conn *pgx.Conn, sqlQuery string) (map[string]string, bool) {
+	results := make(map[string]string)
+
+	rows, err := conn.Query(ctx, sqlQuery)
+	if err == nil {
+		defer rows.Close()
+
+		for rows.Next() {
+			var role, passwordType string
+			if err := rows.Scan(&role, &passwordType); err != nil {
+				fmt.Fprintf(os.Stderr, "Row scan failed: %v\n", err)
+				os.Exit(1)
+			}
+			results[role] = passwordType
+		}
+
+		err = rows.Err()
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == insufficientPrivs {
+		return nil, false
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Query failed: %v\n", err)
+		os.Exit(1)
+	}
+
+	return results, true
+}
+
+func printExplanation(rolesSqlQuery string, passwordsSqlQuery string) {
+	fmt.Println("📖 EXPLANATION")
+	fmt.Println("-------------")
+	fmt.Println("Roles are cluster-wide. Over-privileged or forgotten roles widen the attack surface:")
+	fmt.Println("a leaked password of a SUPERUSER or BYPASSRLS role gives access to all data.")
+	fmt.Println("")
+
+	fmt.Println("🧠 INTERPRETATION")
+	fmt.Println("-----------------")
+	fmt.Println("• PRIVILEGED: SUPERUSER, CREATEROLE, CREATEDB, BYPASSRLS or REPLICATION. Application roles rarely need them.")
+	fmt.Println("• NO PASSWORD EXPIRY: Login role with a password and no VALID UNTIL date.")
+	fmt.Println("• MD5 PASSWORD: md5 is deprecated, use scram-sha-256. Requires superuser to detect (pg_authid).")
+	fmt.Println("• UNLIMITED CONNECTIONS: A single role can exhaust max_connections. Set CONNECTION LIMIT.")
+	fmt.Println("• UNUSED: Login role without sessions, owned objects, grants or memberships. Consider dropping it.")
+	fmt.Println("")
+
+	fmt.Println("💻 SQL QUERY")
+	fmt.Println("------------")
+	util.PrintRunnableSQL(rolesSqlQuery, []interface{}{})
+	fmt.Println("")
+	util.PrintRunnableSQL(passwordsSqlQuery, []interface{}{})
+}
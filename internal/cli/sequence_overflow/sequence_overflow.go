@@ -5,7 +5,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/pg-ok/pgok/internal/db"
 	"github.com/pg-ok/pgok/internal/util"
@@ -16,11 +18,14 @@ import (
 )
 
 type Options struct {
-	DbName  string
-	Schema  string
-	UsedMin float64
-	Explain bool
-	Output  util.OutputFormat
+	DbName         string
+	Schema         string
+	Exclude        util.Exclusion
+	UsedMin        float64
+	SampleInterval time.Duration
+	DaysLeftMax    float64
+	Explain        bool
+	Output         util.OutputFormat
 }
 
 func NewCommand() *cobra.Command {
@@ -44,13 +49,22 @@ func NewCommand() *cobra.Command {
 
 		Run: func(cmd *cobra.Command, args []string) {
 			opts.DbName = args[0]
+
+			if opts.DaysLeftMax > 0 && opts.SampleInterval <= 0 {
+				fmt.Fprintln(os.Stderr, "Error: --days-left-max requires --sample-interval to estimate the consumption rate.")
+				os.Exit(1)
+			}
+
 			run(opts)
 		},
 	}
 
 	flags := command.Flags()
 	flags.StringVar(&opts.Schema, "schema", opts.Schema, "Schema name (use '*' for all user schemas)")
+	util.AddSchemaExclusionFlag(flags, &opts.Exclude)
 	flags.Float64Var(&opts.UsedMin, "used-percent-min", opts.UsedMin, "Filter sequences by minimum used percentage (e.g. 80.0)")
+	flags.DurationVar(&opts.SampleInterval, "sample-interval", 0, "Take two samples this far apart (e.g. 1m) to forecast the exhaustion date")
+	flags.Float64Var(&opts.DaysLeftMax, "days-left-max", 0, "Show only sequences exhausting within this many days (requires --sample-interval)")
 	flags.BoolVar(&opts.Explain, "explain", false, "Print the SQL query and explain the logic/interpretation")
 
 	flags.Var(&opts.Output, "output", "Output format (table, json)")
@@ -62,37 +76,98 @@ func NewCommand() *cobra.Command {
 }
 
 type sequenceUsageRow struct {
-	Schema      string  `json:"schema"`
-	Sequence    string  `json:"sequence"`
-	DataType    string  `json:"data_type"`
-	UsedPercent float64 `json:"used_percent"`
-	LastValue   int64   `json:"last_value"`
-	MaxValue    int64   `json:"max_value"`
+	Schema            string     `json:"schema"`
+	Sequence          string     `json:"sequence"`
+	DataType          string     `json:"data_type"`
+	OwnedBy           string     `json:"owned_by"`
+	ColumnType        string     `json:"column_type"`
+	UsedPercent       float64    `json:"used_percent"`
+	LastValue         int64      `json:"last_value"`
+	MinValue          int64      `json:"min_value"`
+	MaxValue          int64      `json:"max_value"`
+	IncrementBy       int64      `json:"increment_by"`
+	Cycle             bool       `json:"cycle"`
+	ColumnMaxValue    *int64     `json:"column_max_value"` // Pointer to handle NULL (sequence is not owned by a column)
+	EffectiveMinValue int64      `json:"effective_min_value"`
+	EffectiveMaxValue int64      `json:"effective_max_value"`
+	RatePerDay        *float64   `json:"rate_per_day"` // Pointer to handle "not sampled"
+	DaysLeft          *float64   `json:"days_left"`    // Pointer to handle "never" (no consumption or cycling)
+	ExhaustedAt       *time.Time `json:"exhausted_at"` // Pointer to handle "never" (no consumption or cycling)
 }
 
 func run(opts *Options) {
 	manager := db.NewDbManager()
 
+	/*
+	 * The owning column is resolved via pg_depend:
+	 * deptype 'a' = serial / OWNED BY, deptype 'i' = identity column.
+	 * Usage is computed against the smaller range of the sequence and the column type,
+	 * towards MAX for ascending and towards MIN for descending sequences.
+	 */
 	rawSql := `
        WITH sequence_stats AS (
           SELECT
-             schemaname AS schema_name,
-             sequencename AS sequence_name,
-             data_type::TEXT AS data_type,
-             COALESCE(last_value, 0) AS last_value, -- Handle NULL if no permissions
-             max_value,
+             s.schemaname AS schema_name,
+             s.sequencename AS sequence_name,
+             s.data_type::TEXT AS data_type,
+             COALESCE(owner.owned_by, '') AS owned_by,
+             COALESCE(owner.column_type, '') AS column_type,
+             COALESCE(s.last_value, 0) AS last_value, -- Handle NULL if no permissions
+             s.min_value,
+             s.max_value,
+             s.increment_by,
+             s.cycle,
+             owner.column_max_value,
+             GREATEST(s.min_value, -owner.column_max_value - 1) AS effective_min_value,
+             LEAST(s.max_value, owner.column_max_value) AS effective_max_value
+          FROM pg_sequences AS s
+          JOIN pg_namespace AS sn
+            ON sn.nspname = s.schemaname
+          JOIN pg_class AS sc
+            ON sc.relnamespace = sn.oid
+           AND sc.relname = s.sequencename
+          LEFT JOIN LATERAL (
+             SELECT
+                d.refobjid::REGCLASS::TEXT || '.' || a.attname AS owned_by,
+                format_type(a.atttypid, a.atttypmod) AS column_type,
+                CASE a.atttypid
+                   WHEN 'int2'::REGTYPE THEN 32767
+                   WHEN 'int4'::REGTYPE THEN 2147483647
+                   WHEN 'int8'::REGTYPE THEN 9223372036854775807
+                END::BIGINT AS column_max_value
+             FROM pg_depend AS d
+             JOIN pg_attribute AS a
+               ON a.attrelid = d.refobjid
+              AND a.attnum = d.refobjsubid
+             WHERE d.classid = 'pg_class'::REGCLASS
+               AND d.objid = sc.oid
+               AND d.refclassid = 'pg_class'::REGCLASS
+               AND d.refobjsubid > 0
+               AND d.deptype IN ('a', 'i')
+             LIMIT 1
+          ) AS owner ON true
+          WHERE 
+             ($1 = '*' OR s.schemaname = $1)
+             AND s.schemaname NOT IN ('pg_catalog', 'information_schema')
+             AND s.schemaname NOT LIKE 'pg_toast%'
+             AND NOT (s.schemaname = ANY($3::TEXT[]))
+       ),
+       sequence_usage AS (
+          SELECT
+             *,
              COALESCE(ROUND(
-                (COALESCE(last_value, 0)::NUMERIC / NULLIF(max_value::NUMERIC, 0)) * 100.0,
+                (
+                   last_value::NUMERIC / NULLIF(
+                      CASE WHEN increment_by > 0 THEN effective_max_value ELSE effective_min_value END::NUMERIC,
+                      0
+                   )
+                ) * 100.0,
                 2
              )::FLOAT, 0.0) AS percent -- Handle division by zero or NULLs
-          FROM pg_sequences
-          WHERE 
-             ($1 = '*' OR schemaname = $1)
-             AND schemaname NOT IN ('pg_catalog', 'information_schema')
-             AND schemaname NOT LIKE 'pg_toast%'
+          FROM sequence_stats
        )
        SELECT *
-       FROM sequence_stats
+       FROM sequence_usage
        WHERE percent >= $2
        ORDER BY percent DESC;
     `
@@ -117,37 +192,13 @@ func run(opts *Options) {
 		}
 	}(conn, ctx)
 
-	rows, err := conn.Query(ctx, sqlQuery, opts.Schema, opts.UsedMin)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Query failed: %v\n", err)
-		os.Exit(1)
-	}
-	defer rows.Close()
-
-	var results []sequenceUsageRow
+	results := querySequences(ctx, conn, sqlQuery, opts)
 
-	for rows.Next() {
-		var r sequenceUsageRow
+	if opts.SampleInterval > 0 {
+		fmt.Fprintf(os.Stderr, "Sampling sequences, waiting %s for the second sample...\n", opts.SampleInterval)
+		time.Sleep(opts.SampleInterval)
 
-		err := rows.Scan(
-			&r.Schema,
-			&r.Sequence,
-			&r.DataType,
-			&r.LastValue,
-			&r.MaxValue,
-			&r.UsedPercent,
-		)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Row scan failed: %v\n", err)
-			os.Exit(1)
-		}
-
-		results = append(results, r)
-	}
-
-	if rows.Err() != nil {
-		fmt.Fprintf(os.Stderr, "Rows iteration failed: %v\n", rows.Err())
-		os.Exit(1)
+		results = forecast(results, querySequences(ctx, conn, sqlQuery, opts), opts)
 	}
 
 	switch opts.Output {
@@ -163,9 +214,17 @@ func run(opts *Options) {
 
 		fmt.Printf("Checking sequence usage in `%s`\n", opts.DbName)
 		fmt.Printf("Schema: %s\n", schemaDisplay)
+		if opts.SampleInterval > 0 {
+			fmt.Printf("Sample Interval: %s\n", opts.SampleInterval)
+		}
+
+		header := []string{"Schema", "Sequence", "Type (Seq / Column)", "Owned By", "Used % (Current / Limit)"}
+		if opts.SampleInterval > 0 {
+			header = append(header, "Rate/Day", "Exhausted At (Days Left)")
+		}
 
 		table := tablewriter.NewWriter(os.Stdout)
-		table.Header([]string{"Schema", "Sequence", "Type", "Used % (Current / Max)"})
+		table.Header(header)
 
 		for _, row := range results {
 			usedPercentDisplay := fmt.Sprintf("%.2f%%", row.UsedPercent)
@@ -173,17 +232,53 @@ func run(opts *Options) {
 				usedPercentDisplay += " [!]"
 			}
 
+			limit := row.EffectiveMaxValue
+			if row.IncrementBy < 0 {
+				limit = row.EffectiveMinValue
+			}
+
 			usageDisplay := fmt.Sprintf(
 				"%s (%d / %d)",
-				usedPercentDisplay, row.LastValue, row.MaxValue,
+				usedPercentDisplay, row.LastValue, limit,
 			)
 
-			err := table.Append([]string{
+			typeDisplay := row.DataType
+			if row.ColumnType != "" {
+				typeDisplay = fmt.Sprintf("%s / %s", row.DataType, row.ColumnType)
+				if row.EffectiveMaxValue < row.MaxValue {
+					typeDisplay += " [T]"
+				}
+			}
+			if row.IncrementBy < 0 {
+				typeDisplay += " [DESC]"
+			}
+
+			tableRow := []string{
 				row.Schema,
 				row.Sequence,
-				row.DataType,
+				typeDisplay,
+				row.OwnedBy,
 				usageDisplay,
-			})
+			}
+
+			if opts.SampleInterval > 0 {
+				rateDisplay := "-"
+				if row.RatePerDay != nil {
+					rateDisplay = fmt.Sprintf("%.0f", *row.RatePerDay)
+				}
+
+				exhaustedDisplay := "never"
+				switch {
+				case row.Cycle:
+					exhaustedDisplay = "never (cycles)"
+				case row.ExhaustedAt != nil:
+					exhaustedDisplay = fmt.Sprintf("%s (%.0f)", row.ExhaustedAt.Format("2006-01-02"), *row.DaysLeft)
+				}
+
+				tableRow = append(tableRow, rateDisplay, exhaustedDisplay)
+			}
+
+			err := table.Append(tableRow)
 			if err != nil {
 				fmt.Fprintf(os.Stderr, "Error appending table row: %v\n", err)
 			}
@@ -194,7 +289,110 @@ func run(opts *Options) {
 
 		fmt.Println(strings.Repeat("-", 115))
 		fmt.Println("* [!] indicates sequences nearing exhaustion (>80%). INT overflow risk!")
+		fmt.Println("* [T] = The owning column type is smaller than the sequence type. Usage is computed against the column max.")
+		fmt.Println("* [DESC] = Descending sequence. Usage is computed towards its minimum value.")
+		if opts.SampleInterval > 0 {
+			fmt.Println("* Rate/Day is extrapolated from the sample interval. Use a longer interval for a more stable forecast.")
+		}
+	}
+}
+
+func querySequences(ctx context.Context, conn *pgx.Conn, sqlQuery string, opts *Options) []sequenceUsageRow {
+	rows, err := conn.Query(ctx, sqlQuery, opts.Schema, opts.UsedMin, opts.Exclude.Schemas)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Query failed: %v\n", err)
+		os.Exit(1)
+	}
+	defer rows.Close()
+
+	var results []sequenceUsageRow
+
+	for rows.Next() {
+		var r sequenceUsageRow
+
+		err := rows.Scan(
+			&r.Schema,
+			&r.Sequence,
+			&r.DataType,
+			&r.OwnedBy,
+			&r.ColumnType,
+			&r.LastValue,
+			&r.MinValue,
+			&r.MaxValue,
+			&r.IncrementBy,
+			&r.Cycle,
+			&r.ColumnMaxValue,
+			&r.EffectiveMinValue,
+			&r.EffectiveMaxValue,
+			&r.UsedPercent,
+		)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Row scan failed: %v\n", err)
+			os.Exit(1)
+		}
+
+		results = append(results, r)
 	}
+
+	if rows.Err() != nil {
+		fmt.Fprintf(os.Stderr, "Rows iteration failed: %v\n", rows.Err())
+		os.Exit(1)
+	}
+
+	return results
+}
+
+// forecast estimates the consumption rate per day from two samples and projects the exhaustion date.
+// Results are taken from the second sample, sorted by days left (soonest first) and filtered by --days-left-max.
+func forecast(first []sequenceUsageRow, second []sequenceUsageRow, opts *Options) []sequenceUsageRow {
+	firstValues := make(map[string]int64, len(first))
+	for _, r := range first {
+		firstValues[r.Schema+"."+r.Sequence] = r.LastValue
+	}
+
+	now := time.Now()
+	days := opts.SampleInterval.Hours() / 24
+
+	var results []sequenceUsageRow
+
+	for _, r := range second {
+		firstValue, ok := firstValues[r.Schema+"."+r.Sequence]
+		if ok {
+			// Consumption is positive in the direction of the sequence (towards MIN for descending ones)
+			consumed := float64(r.LastValue - firstValue)
+			remaining := float64(r.EffectiveMaxValue - r.LastValue)
+			if r.IncrementBy < 0 {
+				consumed = -consumed
+				remaining = float64(r.LastValue - r.EffectiveMinValue)
+			}
+
+			ratePerDay := consumed / days
+			r.RatePerDay = &ratePerDay
+
+			// A cycling sequence wraps around instead of failing, so it is never exhausted
+			if ratePerDay > 0 && !r.Cycle {
+				daysLeft := remaining / ratePerDay
+				exhaustedAt := now.Add(time.Duration(daysLeft * 24 * float64(time.Hour)))
+				r.DaysLeft = &daysLeft
+				r.ExhaustedAt = &exhaustedAt
+			}
+		}
+
+		if opts.DaysLeftMax > 0 && (r.DaysLeft == nil || *r.DaysLeft > opts.DaysLeftMax) {
+			continue
+		}
+
+		results = append(results, r)
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].DaysLeft == nil || results[j].DaysLeft == nil {
+			return results[j].DaysLeft == nil && results[i].DaysLeft != nil
+		}
+		return *results[i].DaysLeft < *results[j].DaysLeft
+	})
+
+	return results
 }
 
 func printExplanation(sqlQuery string, opts *Options) {
@@ -206,12 +404,18 @@ func printExplanation(sqlQuery string, opts *Options) {
 
 	fmt.Println("🧠 INTERPRETATION")
 	fmt.Println("-----------------")
-	fmt.Println("• Used %: How close the sequence is to its MAX_VALUE.")
+	fmt.Println("• Used %: How close the sequence is to its MAX_VALUE, or to the max of the owning column type")
+	fmt.Println("  when it is smaller (e.g. a bigint sequence feeding an integer column overflows at 2.1B).")
+	fmt.Println("• Owned By: Column resolved via pg_depend (serial / OWNED BY / identity). Sequences used only")
+	fmt.Println("  via DEFAULT nextval(...) without OWNED BY can't be resolved, check them with column:int-overflow.")
 	fmt.Println("• Risk: If > 80-90%, plan a migration to BIGINT immediately.")
+	fmt.Println("• Forecast: With --sample-interval the query runs twice, the difference gives the rate per day")
+	fmt.Println("  and the projected exhaustion date. Descending sequences are measured towards MIN_VALUE,")
+	fmt.Println("  CYCLE sequences wrap around instead of failing, so they have no exhaustion date.")
 	fmt.Println("• Note: 'last_value' might be approximate or require permissions to read.")
 	fmt.Println("")
 
 	fmt.Println("💻 SQL QUERY")
 	fmt.Println("------------")
-	util.PrintRunnableSQL(sqlQuery, []interface{}{opts.Schema, opts.UsedMin})
+	util.PrintRunnableSQL(sqlQuery, []interface{}{opts.Schema, opts.UsedMin, opts.Exclude.Schemas})
 }
@@ -0,0 +1,652 @@
+package settings_audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pg-ok/pgok/internal/config"
+	"github.com/pg-ok/pgok/internal/db"
+	"github.com/pg-ok/pgok/internal/util"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+type Options struct {
+	DbName  string
+	Ram     string
+	Ssd     bool
+	Explain bool
+	Output  util.OutputFormat
+}
+
+func NewCommand() *cobra.Command {
+	opts := &Options{
+		Output: util.OutputFormatTable,
+	}
+
+	command := &cobra.Command{
+		GroupID: "settings",
+
+		Use: "settings:audit [db_name]",
+
+		Short: "Audit server settings against a rule set",
+
+		Long: `Audit pg_settings against built-in rules and custom rules from the config file.
+PostgreSQL cannot detect the server hardware, so RAM and disk type are provided as hints (--ram, --ssd).`,
+
+		Args: cobra.ExactArgs(1),
+
+		Run: func(cmd *cobra.Command, args []string) {
+			opts.DbName = args[0]
+			run(opts)
+		},
+	}
+
+	flags := command.Flags()
+	flags.StringVar(&opts.Ram, "ram", "", "Server RAM hint for RAM-relative rules (e.g. 16GB)")
+	flags.BoolVar(&opts.Ssd, "ssd", false, "Server uses SSD storage (enables SSD-only rules)")
+	flags.BoolVar(&opts.Explain, "explain", false, "Print the SQL query and explain the logic/interpretation")
+
+	flags.Var(&opts.Output, "output", "Output format (table, json)")
+	_ = command.RegisterFlagCompletionFunc("output", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{"table", "json"}, cobra.ShellCompDirectiveDefault
+	})
+
+	return command
+}
+
+const (
+	statusFail = "FAIL"
+	statusOk   = "OK"
+	statusSkip = "SKIP"
+
+	severityCritical = "critical"
+	severityWarning  = "warning"
+	severityInfo     = "info"
+)
+
+// builtinRules are evaluated unless the config file defines rules for the same setting.
+var builtinRules = []config.SettingRule{
+	{
+		Setting:       "shared_buffers",
+		Severity:      severityWarning,
+		MinRamPercent: 15,
+		MaxRamPercent: 40,
+		Message:       "Around 25% of RAM is a good starting point for a dedicated server.",
+	},
+	{
+		Setting:       "work_mem",
+		Severity:      severityWarning,
+		Min:           "4MB",
+		MaxRamPercent: 5,
+		Message:       "Used per sort/hash node: too low spills to temp files, too high risks OOM under concurrency.",
+	},
+	{
+		Setting:  "maintenance_work_mem",
+		Severity: severityInfo,
+		Min:      "256MB",
+		Message:  "Speeds up VACUUM and CREATE INDEX. 256MB-1GB is typical for production servers.",
+	},
+	{
+		Setting:  "random_page_cost",
+		Severity: severityWarning,
+		Max:      "1.5",
+		SsdOnly:  true,
+		Message:  "On SSD random reads are almost as cheap as sequential ones. Use 1.1 to favor index scans.",
+	},
+	{
+		Setting:  "autovacuum",
+		Severity: severityCritical,
+		Equals:   "on",
+		Message:  "Without autovacuum tables bloat and transaction ID wraparound is not prevented.",
+	},
+	{
+		Setting:  "fsync",
+		Severity: severityCritical,
+		Equals:   "on",
+		Message:  "Data can be unrecoverably corrupted after a crash.",
+	},
+	{
+		Setting:  "full_page_writes",
+		Severity: severityCritical,
+		Equals:   "on",
+		Message:  "Torn pages cannot be repaired during crash recovery.",
+	},
+	{
+		Setting:   "log_min_duration_statement",
+		Severity:  severityInfo,
+		NotEquals: "-1",
+		Message:   "Slow queries are not logged. Set a threshold (e.g. 1s) to catch them.",
+	},
+	{
+		Setting:   "idle_in_transaction_session_timeout",
+		Severity:  severityWarning,
+		NotEquals: "0",
+		Message:   "Idle transactions hold locks and block VACUUM until they are closed.",
+	},
+}
+
+type pgSetting struct {
+	Name           string
+	Setting        string
+	Unit           string
+	VarType        string
+	PendingRestart bool
+}
+
+type auditRow struct {
+	Setting  string `json:"setting"`
+	Value    string `json:"value"`
+	Expected string `json:"expected"`
+	Severity string `json:"severity"`
+	Status   string `json:"status"`
+	Message  string `json:"message"`
+}
+
+var memoryUnits = map[string]float64{
+	"B":  1,
+	"kB": 1024,
+	"MB": 1024 * 1024,
+	"GB": 1024 * 1024 * 1024,
+	"TB": 1024 * 1024 * 1024 * 1024,
+}
+
+// Time values are normalized to milliseconds
+var timeUnits = map[string]float64{
+	"us":  0.001,
+	"ms":  1,
+	"s":   1000,
+	"min": 60 * 1000,
+	"h":   60 * 60 * 1000,
+	"d":   24 * 60 * 60 * 1000,
+}
+
+var unitRegexp = regexp.MustCompile(`^([0-9]*)([a-zA-Z]*)$`)
+var valueRegexp = regexp.MustCompile(`^(-?[0-9]*\.?[0-9]+)\s*([a-zA-Z]*)$`)
+
+// parseUnit converts a pg_settings unit (e.g. "8kB", "ms") into a multiplier and a kind ("memory", "time" or "").
+func parseUnit(unit string) (float64, string) {
+	m := unitRegexp.FindStringSubmatch(unit)
+	if m == nil || m[2] == "" {
+		return 1, ""
+	}
+
+	factor := 1.0
+	if m[1] != "" {
+		factor, _ = strconv.ParseFloat(m[1], 64)
+	}
+
+	if mult, ok := memoryUnits[m[2]]; ok {
+		return factor * mult, "memory"
+	}
+	if mult, ok := timeUnits[m[2]]; ok {
+		return factor * mult, "time"
+	}
+
+	return 1, ""
+}
+
+// parseValue normalizes a value like "64MB" or "10s" to bytes or milliseconds.
+// Unitless values are interpreted in the setting's own unit, as in postgresql.conf.
+func parseValue(value string, s pgSetting) (float64, error) {
+	m := valueRegexp.FindStringSubmatch(strings.TrimSpace(value))
+	if m == nil {
+		return 0, fmt.Errorf("invalid value '%s' for %s", value, s.Name)
+	}
+
+	number, err := strconv.ParseFloat(m[1], 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value '%s' for %s", value, s.Name)
+	}
+
+	settingMult, settingKind := parseUnit(s.Unit)
+	if m[2] == "" {
+		return number * settingMult, nil
+	}
+
+	if mult, ok := memoryUnits[m[2]]; ok && settingKind == "memory" {
+		return number * mult, nil
+	}
+	if mult, ok := timeUnits[m[2]]; ok && settingKind == "time" {
+		return number * mult, nil
+	}
+
+	return 0, fmt.Errorf("unit '%s' is not valid for %s", m[2], s.Name)
+}
+
+// formatValue prints a normalized value using the largest unit that represents it exactly.
+func formatValue(value float64, kind string) string {
+	var units []string
+	var multipliers map[string]float64
+
+	switch kind {
+	case "memory":
+		units = []string{"TB", "GB", "MB", "kB"}
+		multipliers = memoryUnits
+	case "time":
+		units = []string{"d", "h", "min", "s", "ms"}
+		multipliers = timeUnits
+	default:
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	}
+
+	if value > 0 {
+		for _, unit := range units {
+			if math.Mod(value, multipliers[unit]) == 0 {
+				return fmt.Sprintf("%.0f%s", value/multipliers[unit], unit)
+			}
+		}
+	}
+
+	suffix := "B"
+	if kind == "time" {
+		suffix = "ms"
+	}
+
+	return strconv.FormatFloat(value, 'f', -1, 64) + suffix
+}
+
+func settingValue(s pgSetting) (float64, bool) {
+	if s.VarType != "integer" && s.VarType != "real" {
+		return 0, false
+	}
+
+	number, err := strconv.ParseFloat(s.Setting, 64)
+	if err != nil {
+		return 0, false
+	}
+
+	mult, _ := parseUnit(s.Unit)
+	return number * mult, true
+}
+
+func displayValue(s pgSetting) string {
+	value, ok := settingValue(s)
+	if !ok || value < 0 {
+		return s.Setting
+	}
+
+	_, kind := parseUnit(s.Unit)
+	return formatValue(value, kind)
+}
+
+// mergeRules replaces built-in rules with config rules for the same setting.
+func mergeRules(custom []config.SettingRule) []config.SettingRule {
+	overridden := make(map[string]bool)
+	for _, rule := range custom {
+		overridden[rule.Setting] = true
+	}
+
+	var rules []config.SettingRule
+	for _, rule := range builtinRules {
+		if !overridden[rule.Setting] {
+			rules = append(rules, rule)
+		}
+	}
+
+	return append(rules, custom...)
+}
+
+// evaluate checks a single rule and returns the status and a human-readable expectation.
+func evaluate(rule config.SettingRule, s pgSetting, opts *Options, ramBytes float64) (string, string, error) {
+	if rule.SsdOnly && !opts.Ssd {
+		return statusSkip, "(--ssd only)", nil
+	}
+
+	_, kind := parseUnit(s.Unit)
+	value, isNumeric := settingValue(s)
+
+	var expected []string
+	status := statusOk
+	checked := false
+
+	compare := func(threshold string, op string) error {
+		checked = true
+		expected = append(expected, op+" "+threshold)
+
+		if !isNumeric {
+			equal := strings.EqualFold(s.Setting, threshold)
+			if (op == "=" && !equal) || (op == "!=" && equal) {
+				status = statusFail
+			}
+			return nil
+		}
+
+		limit, err := parseValue(threshold, s)
+		if err != nil {
+			return err
+		}
+
+		failed := false
+		switch op {
+		case "=":
+			failed = value != limit
+		case "!=":
+			failed = value == limit
+		case ">=":
+			failed = value < limit
+		case "<=":
+			failed = value > limit
+		}
+		if failed {
+			status = statusFail
+		}
+		return nil
+	}
+
+	if rule.Equals != "" {
+		if err := compare(rule.Equals, "="); err != nil {
+			return "", "", err
+		}
+	}
+	if rule.NotEquals != "" {
+		if err := compare(rule.NotEquals, "!="); err != nil {
+			return "", "", err
+		}
+	}
+	if rule.Min != "" {
+		if err := compare(rule.Min, ">="); err != nil {
+			return "", "", err
+		}
+	}
+	if rule.Max != "" {
+		if err := compare(rule.Max, "<="); err != nil {
+			return "", "", err
+		}
+	}
+
+	if rule.MinRamPercent > 0 || rule.MaxRamPercent > 0 {
+		if kind != "memory" || !isNumeric {
+			return "", "", fmt.Errorf("RAM percent rules require a memory setting, got %s", s.Name)
+		}
+
+		if ramBytes <= 0 {
+			expected = append(expected, "(--ram required)")
+		} else {
+			if rule.MinRamPercent > 0 {
+				checked = true
+				limit := ramBytes * rule.MinRamPercent / 100
+				expected = append(expected, fmt.Sprintf(">= %.0f%% RAM (%s)", rule.MinRamPercent, formatValue(math.Round(limit/1024/1024)*1024*1024, kind)))
+				if value < limit {
+					status = statusFail
+				}
+			}
+			if rule.MaxRamPercent > 0 {
+				checked = true
+				limit := ramBytes * rule.MaxRamPercent / 100
+				expected = append(expected, fmt.Sprintf("<= %.0f%% RAM (%s)", rule.MaxRamPercent, formatValue(math.Round(limit/1024/1024)*1024*1024, kind)))
+				if value > limit {
+					status = statusFail
+				}
+			}
+		}
+	}
+
+	if !checked {
+		status = statusSkip
+	}
+
+	return status, strings.Join(expected, ", "), nil
+}
+
+var severityOrder = map[string]int{
+	severityCritical: 0,
+	severityWarning:  1,
+	severityInfo:     2,
+}
+
+func run(opts *Options) {
+	manager := db.NewDbManager()
+
+	ramBytes := 0.0
+	if opts.Ram != "" {
+		// Unitless values are treated as megabytes
+		value, err := parseValue(opts.Ram, pgSetting{Name: "--ram", Unit: "MB"})
+		if err != nil || value <= 0 {
+			fmt.Fprintf(os.Stderr, "Error: invalid --ram value '%s' (expected e.g. 16GB)\n", opts.Ram)
+			os.Exit(1)
+		}
+		ramBytes = value
+	}
+
+	rawSql := `
+       SELECT
+          name,
+          setting,
+          COALESCE(unit, '') AS unit,
+          vartype,
+          pending_restart
+       FROM pg_settings
+       ORDER BY name;
+    `
+
+	sqlQuery := util.TrimLeftSpaces(rawSql)
+
+	rules := mergeRules(manager.GetConfigSettingRules())
+
+	if opts.Explain {
+		printExplanation(sqlQuery, rules)
+		return
+	}
+
+	ctx := context.Background()
+	conn, err := manager.Connect(ctx, opts.DbName)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error connecting to database: %v\n", err)
+		os.Exit(1)
+	}
+	defer func(conn *pgx.Conn, ctx context.Context) {
+		err := conn.Close(ctx)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error closing connection: %v\n", err)
+		}
+	}(conn, ctx)
+
+	rows, err := conn.Query(ctx, sqlQuery)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Query failed: %v\n", err)
+		os.Exit(1)
+	}
+	defer rows.Close()
+
+	settings := make(map[string]pgSetting)
+	var pendingRestart []pgSetting
+
+	for rows.Next() {
+		var s pgSetting
+
+		err := rows.Scan(
+			&s.Name,
+			&s.Setting,
+			&s.Unit,
+			&s.VarType,
+			&s.PendingRestart,
+		)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Row scan failed: %v\n", err)
+			os.Exit(1)
+		}
+
+		settings[s.Name] = s
+		if s.PendingRestart {
+			pendingRestart = append(pendingRestart, s)
+		}
+	}
+
+	if rows.Err() != nil {
+		fmt.Fprintf(os.Stderr, "Rows iteration failed: %v\n", rows.Err())
+		os.Exit(1)
+	}
+
+	var results []auditRow
+
+	for _, rule := range rules {
+		s, ok := settings[rule.Setting]
+		if !ok {
+			// Unknown on this server version (or a typo in the config)
+			fmt.Fprintf(os.Stderr, "Warning: setting '%s' not found, rule skipped\n", rule.Setting)
+			continue
+		}
+
+		status, expected, err := evaluate(rule, s, opts, ramBytes)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: invalid rule: %v\n", err)
+			continue
+		}
+
+		severity := rule.Severity
+		if severity == "" {
+			severity = severityWarning
+		}
+
+		results = append(results, auditRow{
+			Setting:  s.Name,
+			Value:    displayValue(s),
+			Expected: expected,
+			Severity: severity,
+			Status:   status,
+			Message:  rule.Message,
+		})
+	}
+
+	for _, s := range pendingRestart {
+		results = append(results, auditRow{
+			Setting:  s.Name,
+			Value:    displayValue(s),
+			Expected: "applied",
+			Severity: severityWarning,
+			Status:   statusFail,
+			Message:  "Changed in the configuration, but requires a server restart to take effect.",
+		})
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if severityOrder[results[i].Severity] != severityOrder[results[j].Severity] {
+			return severityOrder[results[i].Severity] < severityOrder[results[j].Severity]
+		}
+		return results[i].Setting < results[j].Setting
+	})
+
+	switch opts.Output {
+	case util.OutputFormatJson:
+		jsonData, _ := json.MarshalIndent(results, "", "  ")
+		fmt.Println(string(jsonData))
+
+	default:
+		ramDisplay := "unknown"
+		if opts.Ram != "" {
+			ramDisplay = opts.Ram
+		}
+
+		fmt.Printf("Auditing settings in `%s`\n", opts.DbName)
+		fmt.Printf("RAM: %s, SSD: %t, Rules: %d\n", ramDisplay, opts.Ssd, len(rules))
+
+		var failed, skipped int
+		for _, row := range results {
+			switch row.Status {
+			case statusFail:
+				failed++
+			case statusSkip:
+				skipped++
+			}
+		}
+
+		if failed == 0 {
+			fmt.Println(strings.Repeat("-", 80))
+			fmt.Println("All settings match the rules. Great!")
+		} else {
+			table := tablewriter.NewWriter(os.Stdout)
+			table.Header([]string{"Severity", "Setting", "Value", "Expected", "Message"})
+
+			for _, row := range results {
+				if row.Status != statusFail {
+					continue
+				}
+
+				err := table.Append([]string{
+					strings.ToUpper(row.Severity),
+					row.Setting,
+					row.Value,
+					row.Expected,
+					row.Message,
+				})
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Error appending table row: %v\n", err)
+				}
+			}
+			if err := table.Render(); err != nil {
+				fmt.Fprintf(os.Stderr, "Error rendering table: %v\n", err)
+			}
+
+			fmt.Println(strings.Repeat("-", 80))
+		}
+
+		fmt.Printf("* %d issue(s), %d rule(s) skipped. Passed and skipped rules are listed in --output=json.\n", failed, skipped)
+		if opts.Ram == "" {
+			fmt.Println("* Use --ram=<size> to evaluate RAM-relative rules (e.g. shared_buffers).")
+		}
+		if !opts.Ssd {
+			fmt.Println("* Use --ssd to evaluate SSD-only rules (e.g. random_page_cost).")
+		}
+	}
+}
+
+func printExplanation(sqlQuery string, rules []config.SettingRule) {
+	fmt.Println("📖 EXPLANATION")
+	fmt.Println("-------------")
+	fmt.Println("Default PostgreSQL settings are tuned to start on minimal hardware, not for production.")
+	fmt.Println("This command compares pg_settings with a rule set. Values are normalized to bytes/milliseconds,")
+	fmt.Println("so thresholds can be written with units (e.g. '64MB', '10s').")
+	fmt.Println("")
+
+	fmt.Println("🧠 INTERPRETATION")
+	fmt.Println("-----------------")
+	fmt.Println("• CRITICAL: Risk of data loss or unbounded bloat. Fix immediately.")
+	fmt.Println("• WARNING: Likely performance or stability issue.")
+	fmt.Println("• INFO: Recommendation worth reviewing.")
+	fmt.Println("• Pending restart: The value was changed, but the server must be restarted to apply it.")
+	fmt.Println("• Custom rules: Add [[settings.rules]] to pgok.toml. They replace built-in rules for the same setting.")
+	fmt.Println("")
+
+	fmt.Println("📋 RULES")
+	fmt.Println("--------")
+	for _, rule := range rules {
+		var conditions []string
+		if rule.Equals != "" {
+			conditions = append(conditions, "= "+rule.Equals)
+		}
+		if rule.NotEquals != "" {
+			conditions = append(conditions, "!= "+rule.NotEquals)
+		}
+		if rule.Min != "" {
+			conditions = append(conditions, ">= "+rule.Min)
+		}
+		if rule.Max != "" {
+			conditions = append(conditions, "<= "+rule.Max)
+		}
+		if rule.MinRamPercent > 0 {
+			conditions = append(conditions, fmt.Sprintf(">= %.0f%% RAM", rule.MinRamPercent))
+		}
+		if rule.MaxRamPercent > 0 {
+			conditions = append(conditions, fmt.Sprintf("<= %.0f%% RAM", rule.MaxRamPercent))
+		}
+		if rule.SsdOnly {
+			conditions = append(conditions, "(SSD only)")
+		}
+		fmt.Printf("• %s: %s\n", rule.Setting, strings.Join(conditions, ", "))
+	}
+	fmt.Println("")
+
+	fmt.Println("💻 SQL QUERY")
+	fmt.Println("------------")
+	util.PrintRunnableSQL(sqlQuery, []interface{}{})
+}
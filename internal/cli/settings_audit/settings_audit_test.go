@@ -0,0 +1,65 @@
+package settings_audit
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pg-ok/pgok/internal/config"
+)
+
+func TestEvaluate(t *testing.T) {
+	workMem := pgSetting{Name: "work_mem", Setting: "4096", Unit: "kB", VarType: "integer"}
+	sharedBuffers := pgSetting{Name: "shared_buffers", Setting: "16384", Unit: "8kB", VarType: "integer"}
+	timeout := pgSetting{Name: "statement_timeout", Setting: "30000", Unit: "ms", VarType: "integer"}
+	fsync := pgSetting{Name: "fsync", Setting: "on", VarType: "bool"}
+
+	const gb = 1024 * 1024 * 1024
+
+	tests := []struct {
+		name       string
+		rule       config.SettingRule
+		setting    pgSetting
+		ssd        bool
+		ramBytes   float64
+		wantStatus string
+		wantErr    string
+	}{
+		{name: "min ok", rule: config.SettingRule{Min: "4MB"}, setting: workMem, wantStatus: statusOk},
+		{name: "min fail", rule: config.SettingRule{Min: "8MB"}, setting: workMem, wantStatus: statusFail},
+		{name: "max in setting unit", rule: config.SettingRule{Max: "2048"}, setting: workMem, wantStatus: statusFail},
+		{name: "block unit", rule: config.SettingRule{Equals: "128MB"}, setting: sharedBuffers, wantStatus: statusOk},
+		{name: "time units", rule: config.SettingRule{Min: "30s", Max: "1min"}, setting: timeout, wantStatus: statusOk},
+		{name: "not equals", rule: config.SettingRule{NotEquals: "0"}, setting: timeout, wantStatus: statusOk},
+		{name: "string equals", rule: config.SettingRule{Equals: "ON"}, setting: fsync, wantStatus: statusOk},
+		{name: "string not equals", rule: config.SettingRule{NotEquals: "on"}, setting: fsync, wantStatus: statusFail},
+		{name: "ssd only skipped", rule: config.SettingRule{Min: "1", SsdOnly: true}, setting: workMem, wantStatus: statusSkip},
+		{name: "ssd only checked", rule: config.SettingRule{Min: "1GB", SsdOnly: true}, setting: workMem, ssd: true, wantStatus: statusFail},
+		{name: "ram percent ok", rule: config.SettingRule{MinRamPercent: 0.01}, setting: sharedBuffers, ramBytes: 8 * gb, wantStatus: statusOk},
+		{name: "ram percent fail", rule: config.SettingRule{MinRamPercent: 25}, setting: sharedBuffers, ramBytes: 8 * gb, wantStatus: statusFail},
+		{name: "ram percent without ram", rule: config.SettingRule{MinRamPercent: 25}, setting: sharedBuffers, wantStatus: statusSkip},
+		{name: "no thresholds", rule: config.SettingRule{}, setting: workMem, wantStatus: statusSkip},
+		{name: "wrong unit kind", rule: config.SettingRule{Min: "10s"}, setting: workMem, wantErr: "not valid for work_mem"},
+		{name: "invalid value", rule: config.SettingRule{Min: "lots"}, setting: workMem, wantErr: "invalid value"},
+		{name: "ram percent on time setting", rule: config.SettingRule{MaxRamPercent: 10}, setting: timeout, wantErr: "require a memory setting"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, _, err := evaluate(tt.rule, tt.setting, &Options{Ssd: tt.ssd}, tt.ramBytes)
+
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want it to contain %q", err, tt.wantErr)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if status != tt.wantStatus {
+				t.Errorf("status = %q, want %q", status, tt.wantStatus)
+			}
+		})
+	}
+}
@@ -0,0 +1,318 @@
+package table_cache_hit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/pg-ok/pgok/internal/db"
+	"github.com/pg-ok/pgok/internal/util"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+type Options struct {
+	DbName   string
+	Schema   string
+	Exclude  util.Exclusion
+	CallsMin int64
+	Explain  bool
+	Output   util.OutputFormat
+}
+
+func NewCommand() *cobra.Command {
+	opts := &Options{
+		// Default to scanning all schemas
+		Schema: "*",
+
+		CallsMin: 1000,
+
+		Output: util.OutputFormatTable,
+	}
+
+	command := &cobra.Command{
+		GroupID: "table",
+
+		Use: "table:cache-hit [db_name]",
+
+		Short: "Check table cache efficiency (heap, TOAST and TOAST index Disk Reads vs RAM Hits)",
+
+		Args: cobra.ExactArgs(1),
+
+		Run: func(cmd *cobra.Command, args []string) {
+			opts.DbName = args[0]
+			run(opts)
+		},
+	}
+
+	flags := command.Flags()
+	flags.StringVar(&opts.Schema, "schema", opts.Schema, "Schema name (use '*' for all user schemas)")
+	util.AddExclusionFlags(flags, &opts.Exclude)
+	flags.Int64Var(&opts.CallsMin, "calls-min", opts.CallsMin, "Minimum total block accesses (hits + reads) to include")
+	flags.BoolVar(&opts.Explain, "explain", false, "Print the SQL query and explain the logic/interpretation")
+
+	flags.Var(&opts.Output, "output", "Output format (table, json)")
+	_ = command.RegisterFlagCompletionFunc("output", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{"table", "json"}, cobra.ShellCompDirectiveDefault
+	})
+
+	return command
+}
+
+type databaseCacheHit struct {
+	Database   string  `json:"database"`
+	HitRatio   float64 `json:"hit_ratio"`
+	DiskReads  int64   `json:"disk_reads"`
+	MemoryHits int64   `json:"memory_hits"`
+}
+
+type tableCacheHitRow struct {
+	Schema          string   `json:"schema"`
+	Table           string   `json:"table"`
+	HitRatio        float64  `json:"hit_ratio"`
+	HeapHitRatio    *float64 `json:"heap_hit_ratio"` // Pointers to handle NULL (no accesses or no TOAST table)
+	HeapDiskReads   int64    `json:"heap_disk_reads"`
+	HeapMemoryHits  int64    `json:"heap_memory_hits"`
+	ToastHitRatio   *float64 `json:"toast_hit_ratio"`
+	ToastDiskReads  int64    `json:"toast_disk_reads"`
+	ToastMemoryHits int64    `json:"toast_memory_hits"`
+	TidxHitRatio    *float64 `json:"toast_index_hit_ratio"`
+	TidxDiskReads   int64    `json:"toast_index_disk_reads"`
+	TidxMemoryHits  int64    `json:"toast_index_memory_hits"`
+}
+
+type tableCacheHitResult struct {
+	Stats    *db.StatsAge       `json:"stats"`
+	Database databaseCacheHit   `json:"database"`
+	Tables   []tableCacheHitRow `json:"tables"`
+}
+
+func run(opts *Options) {
+	manager := db.NewDbManager()
+
+	rawDatabaseSql := `
+       SELECT
+          datname AS database_name,
+          ROUND(
+             COALESCE(
+                (blks_hit::NUMERIC / NULLIF(blks_hit + blks_read, 0)) * 100.0,
+                0.0
+             ),
+             2
+          )::FLOAT AS hit_ratio,
+          blks_read AS disk_reads,
+          blks_hit AS memory_hits
+       FROM pg_stat_database
+       WHERE datname = current_database();
+    `
+
+	rawTablesSql := `
+       WITH table_io AS (
+          SELECT
+             schemaname AS schema_name,
+             relname AS table_name,
+             COALESCE(heap_blks_read, 0) AS heap_reads,
+             COALESCE(heap_blks_hit, 0) AS heap_hits,
+             COALESCE(toast_blks_read, 0) AS toast_reads,
+             COALESCE(toast_blks_hit, 0) AS toast_hits,
+             COALESCE(tidx_blks_read, 0) AS tidx_reads,
+             COALESCE(tidx_blks_hit, 0) AS tidx_hits
+          FROM pg_statio_user_tables
+          WHERE 
+            ($1 = '*' OR schemaname = $1)
+            AND schemaname NOT IN ('pg_catalog', 'information_schema')
+            AND schemaname NOT LIKE 'pg_toast%'
+            AND NOT (schemaname = ANY($3::TEXT[]))
+            AND NOT (relname = ANY($4::TEXT[]) OR schemaname || '.' || relname = ANY($4::TEXT[]))
+       )
+       SELECT
+          schema_name,
+          table_name,
+          ROUND(
+             COALESCE(
+                ((heap_hits + toast_hits + tidx_hits)::NUMERIC
+                   / NULLIF(heap_hits + heap_reads + toast_hits + toast_reads + tidx_hits + tidx_reads, 0)) * 100.0,
+                0.0
+             ),
+             2
+          )::FLOAT AS hit_ratio,
+          ROUND((heap_hits::NUMERIC / NULLIF(heap_hits + heap_reads, 0)) * 100.0, 2)::FLOAT AS heap_hit_ratio,
+          heap_reads,
+          heap_hits,
+          ROUND((toast_hits::NUMERIC / NULLIF(toast_hits + toast_reads, 0)) * 100.0, 2)::FLOAT AS toast_hit_ratio,
+          toast_reads,
+          toast_hits,
+          ROUND((tidx_hits::NUMERIC / NULLIF(tidx_hits + tidx_reads, 0)) * 100.0, 2)::FLOAT AS tidx_hit_ratio,
+          tidx_reads,
+          tidx_hits
+       FROM table_io
+       WHERE (heap_hits + heap_reads + toast_hits + toast_reads + tidx_hits + tidx_reads) >= $2
+       ORDER BY hit_ratio ASC;
+    `
+
+	databaseSqlQuery := util.TrimLeftSpaces(rawDatabaseSql)
+	tablesSqlQuery := util.TrimLeftSpaces(rawTablesSql)
+
+	if opts.Explain {
+		printExplanation(databaseSqlQuery, tablesSqlQuery, opts)
+		return
+	}
+
+	ctx := context.Background()
+	conn, err := manager.Connect(ctx, opts.DbName)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error connecting to database: %v\n", err)
+		os.Exit(1)
+	}
+	defer func(conn *pgx.Conn, ctx context.Context) {
+		err := conn.Close(ctx)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error closing connection: %v\n", err)
+		}
+	}(conn, ctx)
+
+	statsAge, err := db.GetStatsAge(ctx, conn)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Query failed: %v\n", err)
+		os.Exit(1)
+	}
+
+	result := tableCacheHitResult{
+		Stats:  statsAge,
+		Tables: []tableCacheHitRow{},
+	}
+
+	err = conn.QueryRow(ctx, databaseSqlQuery).Scan(
+		&result.Database.Database,
+		&result.Database.HitRatio,
+		&result.Database.DiskReads,
+		&result.Database.MemoryHits,
+	)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Query failed: %v\n", err)
+		os.Exit(1)
+	}
+
+	rows, err := conn.Query(ctx, tablesSqlQuery, opts.Schema, opts.CallsMin, opts.Exclude.Schemas, opts.Exclude.Tables)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Query failed: %v\n", err)
+		os.Exit(1)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var r tableCacheHitRow
+
+		err := rows.Scan(
+			&r.Schema,
+			&r.Table,
+			&r.HitRatio,
+			&r.HeapHitRatio,
+			&r.HeapDiskReads,
+			&r.HeapMemoryHits,
+			&r.ToastHitRatio,
+			&r.ToastDiskReads,
+			&r.ToastMemoryHits,
+			&r.TidxHitRatio,
+			&r.TidxDiskReads,
+			&r.TidxMemoryHits,
+		)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Row scan failed: %v\n", err)
+			os.Exit(1)
+		}
+
+		result.Tables = append(result.Tables, r)
+	}
+
+	if rows.Err() != nil {
+		fmt.Fprintf(os.Stderr, "Rows iteration failed: %v\n", rows.Err())
+		os.Exit(1)
+	}
+
+	switch opts.Output {
+	case util.OutputFormatJson:
+		jsonData, _ := json.MarshalIndent(result, "", "  ")
+		fmt.Println(string(jsonData))
+
+	default:
+		fmt.Printf("Analyzing Table Cache Hit Ratio in `%s`\n", opts.DbName)
+
+		schemaDisplay := opts.Schema
+		if opts.Schema == "*" {
+			schemaDisplay = "ALL (except system)"
+		}
+		fmt.Printf("Schema: %s, Min Total Calls: >= %d\n", schemaDisplay, opts.CallsMin)
+		fmt.Printf("Statistics: %s\n", statsAge)
+		fmt.Printf(
+			"Database Ratio: %.2f%% (Disk Reads: %d, Mem Hits: %d)\n",
+			result.Database.HitRatio, result.Database.DiskReads, result.Database.MemoryHits,
+		)
+
+		table := tablewriter.NewWriter(os.Stdout)
+		table.Header([]string{"Table", "Ratio %", "Heap %", "TOAST %", "TOAST Idx %", "Disk Reads", "Mem Hits"})
+
+		for _, row := range result.Tables {
+			err := table.Append([]string{
+				fmt.Sprintf("%s.%s", row.Schema, row.Table),
+				fmt.Sprintf("%.2f%%", row.HitRatio),
+				formatRatio(row.HeapHitRatio),
+				formatRatio(row.ToastHitRatio),
+				formatRatio(row.TidxHitRatio),
+				fmt.Sprintf("%d", row.HeapDiskReads+row.ToastDiskReads+row.TidxDiskReads),
+				fmt.Sprintf("%d", row.HeapMemoryHits+row.ToastMemoryHits+row.TidxMemoryHits),
+			})
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error appending table row: %v\n", err)
+			}
+		}
+		if err := table.Render(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error rendering table: %v\n", err)
+		}
+
+		fmt.Println(strings.Repeat("-", 80))
+		fmt.Println("* Low Ratio (< 95%) means the table is often read from DISK (slow), not RAM.")
+		fmt.Println("* '-' = No accesses (or no TOAST table) for this part of the table.")
+		fmt.Printf("* Hidden tables with total activity < %d calls.\n", opts.CallsMin)
+	}
+}
+
+// formatRatio renders an optional hit ratio, "-" when there were no accesses.
+func formatRatio(ratio *float64) string {
+	if ratio == nil {
+		return "-"
+	}
+	return fmt.Sprintf("%.2f%%", *ratio)
+}
+
+func printExplanation(databaseSqlQuery string, tablesSqlQuery string, opts *Options) {
+	fmt.Println("📖 EXPLANATION")
+	fmt.Println("-------------")
+	fmt.Println("PostgreSQL attempts to keep frequently accessed table blocks in RAM (Shared Buffers).")
+	fmt.Println("When data is found in RAM, it's a 'Hit'. When it must be fetched from disk (or OS cache), it's a 'Read'.")
+	fmt.Println("Large values are stored out of line in a TOAST table, which has its own index.")
+	fmt.Println("")
+
+	fmt.Println("🧠 INTERPRETATION")
+	fmt.Println("-----------------")
+	fmt.Println("• Database Ratio: Overall hit ratio of the database. OLTP workloads should be > 99%.")
+	fmt.Println("• Heap %: Row data. Low values mean the working set of the table does not fit in shared_buffers.")
+	fmt.Println("• TOAST % / TOAST Idx %: Large column values (JSON, text, bytea) read from disk.")
+	fmt.Println("• Statistics age: Ratios cover the whole period since the last statistics reset.")
+	fmt.Println("  A recent reset or restart (cold cache) makes them look worse than in steady state.")
+	fmt.Println("• Action: Increase shared_buffers, reduce the working set (archive old rows, partition),")
+	fmt.Println("  or avoid selecting large columns that are not needed (SELECT *).")
+	fmt.Println("")
+
+	fmt.Println("💻 SQL QUERY")
+	fmt.Println("------------")
+	util.PrintRunnableSQL(db.StatsAgeSql, []interface{}{})
+	fmt.Println("")
+	util.PrintRunnableSQL(databaseSqlQuery, []interface{}{})
+	fmt.Println("")
+	util.PrintRunnableSQL(tablesSqlQuery, []interface{}{opts.Schema, opts.CallsMin, opts.Exclude.Schemas, opts.Exclude.Tables})
+}
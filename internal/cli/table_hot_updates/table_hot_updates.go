@@ -0,0 +1,310 @@
+package table_hot_updates
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/pg-ok/pgok/internal/db"
+	"github.com/pg-ok/pgok/internal/util"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+type Options struct {
+	DbName      string
+	Schema      string
+	Exclude     util.Exclusion
+	UpdatesMin  int64
+	HotRatioMax float64
+	Explain     bool
+	Output      util.OutputFormat
+}
+
+func NewCommand() *cobra.Command {
+	opts := &Options{
+		// Default to scanning all schemas
+		Schema: "*",
+
+		UpdatesMin: 1000,
+
+		HotRatioMax: 100.0,
+
+		Output: util.OutputFormatTable,
+	}
+
+	command := &cobra.Command{
+		GroupID: "table",
+
+		Use: "table:hot-updates [db_name]",
+
+		Short: "Analyze HOT update ratio and advise on fillfactor",
+
+		Long: `Analyze the HOT (Heap-Only Tuple) update ratio of tables with UPDATE traffic.
+Non-HOT updates must insert a new entry into every index of the table, which causes index bloat and write amplification.
+Lowering fillfactor leaves free space on pages so updated rows can stay on the same page (HOT).`,
+
+		Args: cobra.ExactArgs(1),
+
+		Run: func(cmd *cobra.Command, args []string) {
+			opts.DbName = args[0]
+			run(opts)
+		},
+	}
+
+	flags := command.Flags()
+	flags.StringVar(&opts.Schema, "schema", opts.Schema, "Schema name (use '*' for all user schemas)")
+	util.AddExclusionFlags(flags, &opts.Exclude)
+	flags.Int64Var(&opts.UpdatesMin, "updates-min", opts.UpdatesMin, "Minimum number of updated rows to include a table")
+	flags.Float64Var(&opts.HotRatioMax, "hot-ratio-max", opts.HotRatioMax, "Show only tables with HOT ratio (%) lower or equal to this value")
+	flags.BoolVar(&opts.Explain, "explain", false, "Print the SQL query and explain the logic/interpretation")
+
+	flags.Var(&opts.Output, "output", "Output format (table, json)")
+	_ = command.RegisterFlagCompletionFunc("output", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{"table", "json"}, cobra.ShellCompDirectiveDefault
+	})
+
+	return command
+}
+
+// goodHotRatio is the HOT ratio (%) above which a table needs no tuning.
+const goodHotRatio = 90.0
+
+type hotUpdatesRow struct {
+	Schema                      string  `json:"schema"`
+	Table                       string  `json:"table"`
+	Updates                     int64   `json:"updates"`
+	HotUpdates                  int64   `json:"hot_updates"`
+	HotRatio                    float64 `json:"hot_ratio"`
+	Fillfactor                  int64   `json:"fillfactor"`
+	Indexes                     int64   `json:"indexes"`
+	IndexedColumns              int64   `json:"indexed_columns"`
+	Columns                     int64   `json:"columns"`
+	IndexedColumnsLikelyUpdated bool    `json:"indexed_columns_likely_updated"`
+	Recommendation              string  `json:"recommendation"`
+}
+
+type hotUpdatesResult struct {
+	Stats  *db.StatsAge    `json:"stats"`
+	Tables []hotUpdatesRow `json:"tables"`
+}
+
+func run(opts *Options) {
+	manager := db.NewDbManager()
+
+	rawSql := `
+       SELECT
+          s.schemaname AS schema_name,
+          s.relname AS table_name,
+          s.n_tup_upd AS updates,
+          s.n_tup_hot_upd AS hot_updates,
+          ROUND(
+             COALESCE((s.n_tup_hot_upd::NUMERIC / NULLIF(s.n_tup_upd, 0)) * 100.0, 0.0),
+             2
+          )::FLOAT AS hot_ratio,
+          COALESCE((
+             SELECT o.option_value::INT
+             FROM pg_options_to_table(c.reloptions) AS o
+             WHERE o.option_name = 'fillfactor'
+          ), 100) AS fillfactor, -- 100 is the default for tables
+          (
+             SELECT COUNT(*)
+             FROM pg_index AS i
+             WHERE i.indrelid = s.relid
+          ) AS indexes,
+          -- Plain index columns only, expression columns are stored as 0
+          (
+             SELECT COUNT(DISTINCT k)
+             FROM pg_index AS i
+             CROSS JOIN LATERAL UNNEST(i.indkey::int2[]) AS k
+             WHERE i.indrelid = s.relid
+               AND k > 0
+          ) AS indexed_columns,
+          (
+             SELECT COUNT(*)
+             FROM pg_attribute AS a
+             WHERE a.attrelid = s.relid
+               AND a.attnum > 0
+               AND NOT a.attisdropped
+          ) AS columns
+       FROM pg_stat_user_tables AS s
+       JOIN pg_class AS c
+         ON c.oid = s.relid
+       WHERE 
+          ($1 = '*' OR s.schemaname = $1)
+          AND s.schemaname NOT IN ('pg_catalog', 'information_schema')
+          AND s.schemaname NOT LIKE 'pg_toast%'
+          AND NOT (s.schemaname = ANY($4::TEXT[]))
+          AND NOT (s.relname = ANY($5::TEXT[]) OR s.schemaname || '.' || s.relname = ANY($5::TEXT[]))
+          AND s.n_tup_upd >= $2
+          AND COALESCE((s.n_tup_hot_upd::NUMERIC / NULLIF(s.n_tup_upd, 0)) * 100.0, 0.0) <= $3
+       ORDER BY (s.n_tup_upd - s.n_tup_hot_upd) DESC;
+    `
+
+	sqlQuery := util.TrimLeftSpaces(rawSql)
+
+	if opts.Explain {
+		printExplanation(sqlQuery, opts)
+		return
+	}
+
+	ctx := context.Background()
+	conn, err := manager.Connect(ctx, opts.DbName)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error connecting to database: %v\n", err)
+		os.Exit(1)
+	}
+	defer func(conn *pgx.Conn, ctx context.Context) {
+		err := conn.Close(ctx)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error closing connection: %v\n", err)
+		}
+	}(conn, ctx)
+
+	statsAge, err := db.GetStatsAge(ctx, conn)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Query failed: %v\n", err)
+		os.Exit(1)
+	}
+
+	rows, err := conn.Query(ctx, sqlQuery, opts.Schema, opts.UpdatesMin, opts.HotRatioMax, opts.Exclude.Schemas, opts.Exclude.Tables)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Query failed: %v\n", err)
+		os.Exit(1)
+	}
+	defer rows.Close()
+
+	result := hotUpdatesResult{
+		Stats:  statsAge,
+		Tables: []hotUpdatesRow{},
+	}
+
+	for rows.Next() {
+		var r hotUpdatesRow
+
+		err := rows.Scan(
+			&r.Schema,
+			&r.Table,
+			&r.Updates,
+			&r.HotUpdates,
+			&r.HotRatio,
+			&r.Fillfactor,
+			&r.Indexes,
+			&r.IndexedColumns,
+			&r.Columns,
+		)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Row scan failed: %v\n", err)
+			os.Exit(1)
+		}
+
+		advise(&r)
+
+		result.Tables = append(result.Tables, r)
+	}
+
+	if rows.Err() != nil {
+		fmt.Fprintf(os.Stderr, "Rows iteration failed: %v\n", rows.Err())
+		os.Exit(1)
+	}
+
+	switch opts.Output {
+	case util.OutputFormatJson:
+		jsonData, _ := json.MarshalIndent(result, "", "  ")
+		fmt.Println(string(jsonData))
+
+	default:
+		schemaDisplay := opts.Schema
+		if opts.Schema == "*" {
+			schemaDisplay = "ALL (except system)"
+		}
+
+		fmt.Printf("Analyzing HOT updates in `%s`\n", opts.DbName)
+		fmt.Printf("Schema: %s, Updates Min: >= %d, HOT Ratio Max: <= %.2f%%\n", schemaDisplay, opts.UpdatesMin, opts.HotRatioMax)
+		fmt.Printf("Statistics: %s\n", statsAge)
+
+		if len(result.Tables) == 0 {
+			fmt.Println(strings.Repeat("-", 80))
+			fmt.Println("No tables with UPDATE traffic found within the specified criteria.")
+			return
+		}
+
+		table := tablewriter.NewWriter(os.Stdout)
+		table.Header([]string{"Table", "Updates", "HOT %", "Fillfactor", "Indexed Cols", "Recommendation"})
+
+		for _, row := range result.Tables {
+			err := table.Append([]string{
+				fmt.Sprintf("%s.%s", row.Schema, row.Table),
+				fmt.Sprintf("%d", row.Updates),
+				fmt.Sprintf("%.2f%%", row.HotRatio),
+				fmt.Sprintf("%d", row.Fillfactor),
+				fmt.Sprintf("%d / %d", row.IndexedColumns, row.Columns),
+				row.Recommendation,
+			})
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error appending table row: %v\n", err)
+			}
+		}
+		if err := table.Render(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error rendering table: %v\n", err)
+		}
+
+		fmt.Println(strings.Repeat("-", 80))
+		fmt.Println("* Sorted by non-HOT updates: tables on the top produce the most index write amplification.")
+		fmt.Println("* Fillfactor applies to new pages only. Rewrite the table (VACUUM FULL, pg_repack) to apply it to existing data.")
+	}
+}
+
+// advise fills the recommendation for a table based on its HOT ratio, fillfactor and index coverage.
+// HOT updates are impossible when an indexed column changes, so lowering fillfactor helps only
+// when updates touch non-indexed columns.
+func advise(r *hotUpdatesRow) {
+	indexedShare := 0.0
+	if r.Columns > 0 {
+		indexedShare = float64(r.IndexedColumns) / float64(r.Columns)
+	}
+
+	switch {
+	case r.HotRatio >= goodHotRatio:
+		r.Recommendation = "OK"
+	case r.Fillfactor < 100:
+		// Free space is already reserved, yet updates are not HOT
+		r.IndexedColumnsLikelyUpdated = true
+		r.Recommendation = "Updates likely change indexed columns, review indexes"
+	case indexedShare >= 0.5:
+		r.IndexedColumnsLikelyUpdated = true
+		r.Recommendation = "Lower fillfactor (e.g. 90), but most columns are indexed"
+	default:
+		r.Recommendation = "Lower fillfactor (e.g. 90)"
+	}
+}
+
+func printExplanation(sqlQuery string, opts *Options) {
+	fmt.Println("📖 EXPLANATION")
+	fmt.Println("-------------")
+	fmt.Println("An UPDATE in PostgreSQL writes a new row version. If no indexed column changes and the page")
+	fmt.Println("has free space, it is a HOT (Heap-Only Tuple) update: indexes are not touched at all.")
+	fmt.Println("Otherwise, a new entry is inserted into EVERY index of the table (write amplification, index bloat).")
+	fmt.Println("")
+
+	fmt.Println("🧠 INTERPRETATION")
+	fmt.Println("-----------------")
+	fmt.Println("• HOT %: Share of updates that were HOT. Above 90% is good for update-heavy tables.")
+	fmt.Println("• Fillfactor: Percentage of each page filled by INSERTs. 100 (default) leaves no room for HOT updates.")
+	fmt.Println("• Indexed Cols: Distinct columns used by plain indexes vs all columns. If an updated column is indexed,")
+	fmt.Println("  the update can never be HOT. Low HOT % despite a lowered fillfactor points to this case.")
+	fmt.Println("• Statistics age: Counters cover the period since the last statistics reset. After changing fillfactor,")
+	fmt.Println("  reset the statistics (or wait long enough) to see the effect in HOT %.")
+	fmt.Println("• Action: ALTER TABLE ... SET (fillfactor = 90), or drop/adjust indexes on frequently updated columns.")
+	fmt.Println("")
+
+	fmt.Println("💻 SQL QUERY")
+	fmt.Println("------------")
+	util.PrintRunnableSQL(db.StatsAgeSql, []interface{}{})
+	fmt.Println("")
+	util.PrintRunnableSQL(sqlQuery, []interface{}{opts.Schema, opts.UpdatesMin, opts.HotRatioMax, opts.Exclude.Schemas, opts.Exclude.Tables})
+}
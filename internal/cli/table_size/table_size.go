@@ -0,0 +1,310 @@
+package table_size
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/pg-ok/pgok/internal/db"
+	"github.com/pg-ok/pgok/internal/util"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+type Options struct {
+	DbName     string
+	Schema     string
+	Exclude    util.Exclusion
+	SizeMin    int64
+	Partitions bool
+	Explain    bool
+	Output     util.OutputFormat
+}
+
+func NewCommand() *cobra.Command {
+	opts := &Options{
+		// Default to scanning all schemas
+		Schema: "*",
+
+		SizeMin: 0,
+
+		Output: util.OutputFormatTable,
+	}
+
+	command := &cobra.Command{
+		GroupID: "table",
+
+		Use: "table:size [db_name]",
+
+		Short: "Show table sizes (heap, TOAST, indexes) sorted by total size (descending)",
+
+		Args: cobra.ExactArgs(1),
+
+		Run: func(cmd *cobra.Command, args []string) {
+			opts.DbName = args[0]
+			run(opts)
+		},
+	}
+
+	flags := command.Flags()
+	flags.StringVar(&opts.Schema, "schema", opts.Schema, "Schema name (use '*' for all user schemas)")
+	util.AddExclusionFlags(flags, &opts.Exclude)
+	flags.Int64Var(&opts.SizeMin, "size-min", opts.SizeMin, "Minimum total table size in bytes (exclude smaller tables)")
+	flags.BoolVar(&opts.Partitions, "partitions", false, "Show individual partitions instead of rolling them up to the partitioned parent")
+	flags.BoolVar(&opts.Explain, "explain", false, "Print the SQL query and explain the logic/interpretation")
+
+	flags.Var(&opts.Output, "output", "Output format (table, json)")
+	_ = command.RegisterFlagCompletionFunc("output", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{"table", "json"}, cobra.ShellCompDirectiveDefault
+	})
+
+	return command
+}
+
+type tableSizeRow struct {
+	Schema         string   `json:"schema"`
+	Table          string   `json:"table"`
+	Kind           string   `json:"kind"`
+	Partitions     int64    `json:"partitions"`
+	RowsEstimate   int64    `json:"rows_estimate"`
+	HeapBytes      int64    `json:"heap_bytes"`
+	HeapHuman      string   `json:"heap_human"`
+	ToastBytes     int64    `json:"toast_bytes"`
+	ToastHuman     string   `json:"toast_human"`
+	IndexesBytes   int64    `json:"indexes_bytes"`
+	IndexesHuman   string   `json:"indexes_human"`
+	TotalBytes     int64    `json:"total_bytes"`
+	TotalHuman     string   `json:"total_human"`
+	IndexHeapRatio *float64 `json:"index_heap_ratio"` // Pointer to handle NULL (empty heap)
+}
+
+func run(opts *Options) {
+	manager := db.NewDbManager()
+
+	/*
+	 * partition_roots maps every partition (at any depth) to its top-level partitioned table
+	 * by walking pg_inherits down from the roots.
+	 * Sizes are then summed per root, or per relation when $3 (--partitions) is set.
+	 */
+	rawSql := `
+       WITH RECURSIVE partition_roots AS (
+          SELECT
+             i.inhrelid AS relid,
+             i.inhparent AS root_relid
+          FROM pg_inherits AS i
+          JOIN pg_class AS p
+            ON p.oid = i.inhparent
+          WHERE p.relkind = 'p'
+            AND NOT p.relispartition
+
+          UNION ALL
+
+          SELECT
+             i.inhrelid AS relid,
+             pr.root_relid
+          FROM pg_inherits AS i
+          JOIN partition_roots AS pr
+            ON pr.relid = i.inhparent
+       ),
+       relation_sizes AS (
+          SELECT
+             rt.oid AS report_relid,
+             c.relkind,
+             GREATEST(c.reltuples, 0)::BIGINT AS rows_estimate,
+             pg_relation_size(c.oid) AS heap_bytes,
+             -- pg_table_size = heap + TOAST + free space map + visibility map
+             pg_table_size(c.oid) - pg_relation_size(c.oid) AS toast_bytes,
+             pg_indexes_size(c.oid) AS indexes_bytes,
+             pg_total_relation_size(c.oid) AS total_bytes
+          FROM pg_class AS c
+          LEFT JOIN partition_roots AS pr
+            ON pr.relid = c.oid
+          -- The reported table: the partition itself or its root partitioned table
+          JOIN pg_class AS rt
+            ON rt.oid = CASE WHEN $3 THEN c.oid ELSE COALESCE(pr.root_relid, c.oid) END
+          JOIN pg_namespace AS rn
+            ON rn.oid = rt.relnamespace
+          -- Filtered here, so sizes are computed only for the reported tables
+          WHERE c.relkind IN ('r', 'p')
+            -- Partitioned parents hold no data themselves, skip them when listing partitions
+            AND NOT ($3 AND c.relkind = 'p')
+            AND ($1 = '*' OR rn.nspname = $1)
+            AND rn.nspname NOT IN ('pg_catalog', 'information_schema')
+            AND rn.nspname NOT LIKE 'pg_toast%'
+            AND NOT (rn.nspname = ANY($4::TEXT[]))
+            AND NOT (rt.relname = ANY($5::TEXT[]) OR rn.nspname || '.' || rt.relname = ANY($5::TEXT[]))
+       )
+       SELECT
+          n.nspname AS schema_name,
+          t.relname AS table_name,
+          CASE
+             WHEN t.relkind = 'p' THEN 'PARTITIONED'
+             WHEN t.relispartition THEN 'PARTITION'
+             ELSE 'TABLE'
+          END AS kind,
+          CASE
+             WHEN t.relkind = 'p' THEN COUNT(*) FILTER (WHERE s.relkind = 'r')
+             ELSE 0
+          END AS partitions,
+          SUM(s.rows_estimate)::BIGINT AS rows_estimate,
+          SUM(s.heap_bytes)::BIGINT AS heap_bytes,
+          pg_size_pretty(SUM(s.heap_bytes)) AS heap_human,
+          SUM(s.toast_bytes)::BIGINT AS toast_bytes,
+          pg_size_pretty(SUM(s.toast_bytes)) AS toast_human,
+          SUM(s.indexes_bytes)::BIGINT AS indexes_bytes,
+          pg_size_pretty(SUM(s.indexes_bytes)) AS indexes_human,
+          SUM(s.total_bytes)::BIGINT AS total_bytes,
+          pg_size_pretty(SUM(s.total_bytes)) AS total_human,
+          ROUND(SUM(s.indexes_bytes)::NUMERIC / NULLIF(SUM(s.heap_bytes), 0), 2)::FLOAT AS index_heap_ratio
+       FROM relation_sizes AS s
+       JOIN pg_class AS t
+         ON t.oid = s.report_relid
+       JOIN pg_namespace AS n
+         ON n.oid = t.relnamespace
+       GROUP BY n.nspname, t.relname, t.relkind, t.relispartition, t.oid
+       HAVING SUM(s.total_bytes) >= $2
+       ORDER BY total_bytes DESC;
+    `
+
+	sqlQuery := util.TrimLeftSpaces(rawSql)
+
+	if opts.Explain {
+		printExplanation(sqlQuery, opts)
+		return
+	}
+
+	ctx := context.Background()
+	conn, err := manager.Connect(ctx, opts.DbName)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error connecting to database: %v\n", err)
+		os.Exit(1)
+	}
+	defer func(conn *pgx.Conn, ctx context.Context) {
+		err := conn.Close(ctx)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error closing connection: %v\n", err)
+		}
+	}(conn, ctx)
+
+	rows, err := conn.Query(ctx, sqlQuery, opts.Schema, opts.SizeMin, opts.Partitions, opts.Exclude.Schemas, opts.Exclude.Tables)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Query failed: %v\n", err)
+		os.Exit(1)
+	}
+	defer rows.Close()
+
+	var results []tableSizeRow
+
+	for rows.Next() {
+		var r tableSizeRow
+
+		err := rows.Scan(
+			&r.Schema,
+			&r.Table,
+			&r.Kind,
+			&r.Partitions,
+			&r.RowsEstimate,
+			&r.HeapBytes,
+			&r.HeapHuman,
+			&r.ToastBytes,
+			&r.ToastHuman,
+			&r.IndexesBytes,
+			&r.IndexesHuman,
+			&r.TotalBytes,
+			&r.TotalHuman,
+			&r.IndexHeapRatio,
+		)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Row scan failed: %v\n", err)
+			os.Exit(1)
+		}
+
+		results = append(results, r)
+	}
+
+	if rows.Err() != nil {
+		fmt.Fprintf(os.Stderr, "Rows iteration failed: %v\n", rows.Err())
+		os.Exit(1)
+	}
+
+	switch opts.Output {
+	case util.OutputFormatJson:
+		jsonData, _ := json.MarshalIndent(results, "", "  ")
+		fmt.Println(string(jsonData))
+
+	default:
+		schemaDisplay := opts.Schema
+		if opts.Schema == "*" {
+			schemaDisplay = "ALL (except system)"
+		}
+
+		fmt.Printf("Analyzing table sizes in database `%s`\n", opts.DbName)
+		fmt.Printf("Schema: %s, Size Min: >= %d bytes\n", schemaDisplay, opts.SizeMin)
+
+		table := tablewriter.NewWriter(os.Stdout)
+		table.Header([]string{"Schema", "Table", "Rows (Est.)", "Heap", "TOAST", "Indexes", "Total", "Idx/Heap"})
+
+		for _, row := range results {
+			tableDisplay := row.Table
+			switch row.Kind {
+			case "PARTITIONED":
+				tableDisplay += fmt.Sprintf(" [P:%d]", row.Partitions)
+			case "PARTITION":
+				tableDisplay += " [part]"
+			}
+
+			ratioDisplay := "-"
+			if row.IndexHeapRatio != nil {
+				ratioDisplay = fmt.Sprintf("%.2f", *row.IndexHeapRatio)
+			}
+
+			err := table.Append([]string{
+				row.Schema,
+				tableDisplay,
+				fmt.Sprintf("%d", row.RowsEstimate),
+				row.HeapHuman,
+				row.ToastHuman,
+				row.IndexesHuman,
+				row.TotalHuman,
+				ratioDisplay,
+			})
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error appending table row: %v\n", err)
+			}
+		}
+		if err := table.Render(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error rendering table: %v\n", err)
+		}
+
+		fmt.Println(strings.Repeat("-", 100))
+		if !opts.Partitions {
+			fmt.Println("* [P:N] = Partitioned table, sizes are summed over its N partitions. Use --partitions to list them.")
+		}
+		fmt.Println("* Idx/Heap > 1 means indexes are bigger than the data itself. Check index:size and index:unused.")
+	}
+}
+
+func printExplanation(sqlQuery string, opts *Options) {
+	fmt.Println("📖 EXPLANATION")
+	fmt.Println("-------------")
+	fmt.Println("The disk footprint of a table is split between the heap (row data), TOAST")
+	fmt.Println("(large values stored out of line) and its indexes.")
+	fmt.Println("Partitions are rolled up to their top-level partitioned table via pg_inherits.")
+	fmt.Println("")
+
+	fmt.Println("🧠 INTERPRETATION")
+	fmt.Println("-----------------")
+	fmt.Println("• Heap: pg_relation_size. TOAST: pg_table_size minus heap (includes free space and visibility maps).")
+	fmt.Println("• Indexes: pg_indexes_size. Total: pg_total_relation_size.")
+	fmt.Println("• Rows: Estimate from the last ANALYZE (pg_class.reltuples).")
+	fmt.Println("• Idx/Heap: High ratio may indicate too many, unused or bloated indexes.")
+	fmt.Println("")
+
+	fmt.Println("💻 SQL QUERY")
+	fmt.Println("------------")
+	util.PrintRunnableSQL(sqlQuery, []interface{}{opts.Schema, opts.SizeMin, opts.Partitions, opts.Exclude.Schemas, opts.Exclude.Tables})
+}
@@ -0,0 +1,269 @@
+package table_unused
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/pg-ok/pgok/internal/db"
+	"github.com/pg-ok/pgok/internal/util"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+type Options struct {
+	DbName            string
+	Schema            string
+	Exclude           util.Exclusion
+	IncludeReferenced bool
+	Explain           bool
+	Output            util.OutputFormat
+}
+
+func NewCommand() *cobra.Command {
+	opts := &Options{
+		// Default to scanning all schemas
+		Schema: "*",
+
+		Output: util.OutputFormatTable,
+	}
+
+	command := &cobra.Command{
+		GroupID: "table",
+
+		Use: "table:unused [db_name]",
+
+		Short: "Find tables that were never read or written since statistics reset",
+
+		Args: cobra.ExactArgs(1),
+
+		Run: func(cmd *cobra.Command, args []string) {
+			opts.DbName = args[0]
+			run(opts)
+		},
+	}
+
+	flags := command.Flags()
+	flags.StringVar(&opts.Schema, "schema", opts.Schema, "Schema name (use '*' for all user schemas)")
+	util.AddExclusionFlags(flags, &opts.Exclude)
+	flags.BoolVar(&opts.IncludeReferenced, "include-referenced", false, "Include tables referenced by Foreign Keys of active tables")
+	flags.BoolVar(&opts.Explain, "explain", false, "Print the SQL query and explain the logic/interpretation")
+
+	flags.Var(&opts.Output, "output", "Output format (table, json)")
+	_ = command.RegisterFlagCompletionFunc("output", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{"table", "json"}, cobra.ShellCompDirectiveDefault
+	})
+
+	return command
+}
+
+type unusedTableRow struct {
+	Schema       string   `json:"schema"`
+	Table        string   `json:"table"`
+	SizeHuman    string   `json:"size_human"`
+	SizeBytes    int64    `json:"size_bytes"`
+	RowsEstimate int64    `json:"rows_estimate"`
+	ReferencedBy []string `json:"referenced_by"`
+}
+
+type unusedTablesResult struct {
+	Stats  *db.StatsAge     `json:"stats"`
+	Tables []unusedTableRow `json:"tables"`
+}
+
+func run(opts *Options) {
+	manager := db.NewDbManager()
+
+	/*
+	 * "Active" table = has any scan or any written tuple since statistics reset.
+	 * referenced_by lists active tables having a Foreign Key to the unused table:
+	 * the table is still needed for their integrity checks, so it is hidden unless --include-referenced is set.
+	 */
+	rawSql := `
+       WITH table_activity AS (
+          SELECT
+             relid,
+             (
+                seq_scan > 0
+                OR COALESCE(idx_scan, 0) > 0
+                OR n_tup_ins + n_tup_upd + n_tup_del > 0
+             ) AS is_active
+          FROM pg_stat_user_tables
+       )
+       SELECT
+          s.schemaname AS schema_name,
+          s.relname AS table_name,
+          pg_size_pretty(pg_total_relation_size(s.relid)) AS size_human,
+          pg_total_relation_size(s.relid) AS size_bytes,
+          GREATEST(c.reltuples, 0)::BIGINT AS rows_estimate,
+          ARRAY(
+             SELECT DISTINCT f.conrelid::REGCLASS::TEXT
+             FROM pg_constraint AS f
+             JOIN table_activity AS fa
+               ON fa.relid = f.conrelid
+             WHERE f.contype = 'f'
+               AND f.confrelid = s.relid
+               AND f.conrelid <> s.relid
+               AND fa.is_active
+          ) AS referenced_by
+       FROM pg_stat_user_tables AS s
+       JOIN pg_class AS c
+         ON c.oid = s.relid
+       JOIN table_activity AS a
+         ON a.relid = s.relid
+       WHERE 
+          ($1 = '*' OR s.schemaname = $1)
+          AND NOT a.is_active
+          AND NOT (s.schemaname = ANY($2::TEXT[]))
+          AND NOT (s.relname = ANY($3::TEXT[]) OR s.schemaname || '.' || s.relname = ANY($3::TEXT[]))
+       ORDER BY size_bytes DESC, schema_name, table_name;
+    `
+
+	sqlQuery := util.TrimLeftSpaces(rawSql)
+
+	if opts.Explain {
+		printExplanation(sqlQuery, opts)
+		return
+	}
+
+	ctx := context.Background()
+	conn, err := manager.Connect(ctx, opts.DbName)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error connecting to database: %v\n", err)
+		os.Exit(1)
+	}
+	defer func(conn *pgx.Conn, ctx context.Context) {
+		err := conn.Close(ctx)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error closing connection: %v\n", err)
+		}
+	}(conn, ctx)
+
+	statsAge, err := db.GetStatsAge(ctx, conn)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Query failed: %v\n", err)
+		os.Exit(1)
+	}
+
+	rows, err := conn.Query(ctx, sqlQuery, opts.Schema, opts.Exclude.Schemas, opts.Exclude.Tables)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Query failed: %v\n", err)
+		os.Exit(1)
+	}
+	defer rows.Close()
+
+	result := unusedTablesResult{
+		Stats:  statsAge,
+		Tables: []unusedTableRow{},
+	}
+
+	for rows.Next() {
+		var r unusedTableRow
+
+		err := rows.Scan(
+			&r.Schema,
+			&r.Table,
+			&r.SizeHuman,
+			&r.SizeBytes,
+			&r.RowsEstimate,
+			&r.ReferencedBy,
+		)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Row scan failed: %v\n", err)
+			os.Exit(1)
+		}
+
+		if len(r.ReferencedBy) > 0 && !opts.IncludeReferenced {
+			continue
+		}
+
+		result.Tables = append(result.Tables, r)
+	}
+
+	if rows.Err() != nil {
+		fmt.Fprintf(os.Stderr, "Rows iteration failed: %v\n", rows.Err())
+		os.Exit(1)
+	}
+
+	switch opts.Output {
+	case util.OutputFormatJson:
+		jsonData, _ := json.MarshalIndent(result, "", "  ")
+		fmt.Println(string(jsonData))
+
+	default:
+		schemaDisplay := opts.Schema
+		if opts.Schema == "*" {
+			schemaDisplay = "ALL (except system)"
+		}
+
+		fmt.Printf("Searching for unused tables in `%s`\n", opts.DbName)
+		fmt.Printf("Schema: %s\n", schemaDisplay)
+		fmt.Printf("Statistics: %s\n", statsAge)
+
+		if len(result.Tables) == 0 {
+			fmt.Println(strings.Repeat("-", 80))
+			fmt.Println("No unused tables found. Every table is read or written.")
+			return
+		}
+
+		table := tablewriter.NewWriter(os.Stdout)
+		table.Header([]string{"Schema", "Table", "Size", "Rows (Est.)", "Referenced By (Active)"})
+
+		for _, row := range result.Tables {
+			err := table.Append([]string{
+				row.Schema,
+				row.Table,
+				row.SizeHuman,
+				fmt.Sprintf("%d", row.RowsEstimate),
+				strings.Join(row.ReferencedBy, "\n"),
+			})
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error appending table row: %v\n", err)
+			}
+		}
+		if err := table.Render(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error rendering table: %v\n", err)
+		}
+
+		fmt.Println(strings.Repeat("-", 80))
+		if days, exact := statsAge.Days(); days < 30 {
+			if exact {
+				fmt.Printf("* Warning: Statistics cover only %.0f days. Tables used monthly or yearly may show up here.\n", days)
+			} else {
+				fmt.Printf("* Warning: Statistics were never reset, the covered period is unknown (at least %.0f days). Tables used monthly or yearly may show up here.\n", days)
+			}
+		}
+		if !opts.IncludeReferenced {
+			fmt.Println("* Tables referenced by Foreign Keys of active tables are hidden. Use --include-referenced to show them.")
+		}
+		fmt.Println("* Be careful! Check application code, cron jobs and reports before dropping anything.")
+	}
+}
+
+func printExplanation(sqlQuery string, opts *Options) {
+	fmt.Println("📖 EXPLANATION")
+	fmt.Println("-------------")
+	fmt.Println("Tables left over from old features keep consuming disk space, backup time and VACUUM effort.")
+	fmt.Println("PostgreSQL counts every scan and every written row per table since statistics were last reset.")
+	fmt.Println("")
+
+	fmt.Println("🧠 INTERPRETATION")
+	fmt.Println("-----------------")
+	fmt.Println("• Unused: 0 sequential scans, 0 index scans and 0 inserted/updated/deleted rows.")
+	fmt.Println("• Statistics age: Findings are only as good as the period covered by the statistics.")
+	fmt.Println("  A few days after a reset (or a failover to a replica) most tables may look unused.")
+	fmt.Println("• Referenced By: Active tables with a Foreign Key to this table. FK checks don't count as scans,")
+	fmt.Println("  but the table is still required. Hidden unless --include-referenced is set.")
+	fmt.Println("• Action: Confirm with the owners of the data, then archive and DROP the table.")
+	fmt.Println("")
+
+	fmt.Println("💻 SQL QUERY")
+	fmt.Println("------------")
+	util.PrintRunnableSQL(db.StatsAgeSql, []interface{}{})
+	fmt.Println("")
+	util.PrintRunnableSQL(sqlQuery, []interface{}{opts.Schema, opts.Exclude.Schemas, opts.Exclude.Tables})
+}
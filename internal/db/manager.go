@@ -2,7 +2,9 @@ package db
 
 import (
 	"context"
+	"fmt"
 	"net/url"
+	"os"
 	"strings"
 
 	"github.com/pg-ok/pgok/internal/config"
@@ -15,26 +17,70 @@ type DbManager struct {
 }
 
 func NewDbManager() *DbManager {
+	cfg, err := config.Current()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
 	return &DbManager{
-		config: config.Load(),
+		config: cfg,
 	}
 }
 
 // Connect establishes a connection to the database.
-// If `dbUriOrConfigName` starts with "postgres://" or "postgresql://" -> treat as a direct connection URI.
-// Otherwise -> treat as an alias and look it up in the config.
+// `dbUriOrConfigName` can be:
+// - a connection URI: starts with "postgres://" or "postgresql://";
+// - a key/value DSN: contains "=" (e.g. "host=localhost dbname=app" or "service=billing"),
+// an empty string means "use PG* environment variables only";
+// - otherwise -> an alias, looked up in the config.
+// Parameters missing from the connection string are resolved like libpq does:
+// PG* environment variables, pg_service.conf entries (service=...) and the ~/.pgpass password file.
 func (m *DbManager) Connect(ctx context.Context, dbUriOrConfigName string) (*pgx.Conn, error) {
-	var rawURI string
-
-	if strings.HasPrefix(dbUriOrConfigName, "postgres://") || strings.HasPrefix(dbUriOrConfigName, "postgresql://") {
-		rawURI = dbUriOrConfigName
-	} else {
-		rawURI = m.config.GetDbURI(dbUriOrConfigName)
+	rawURI := dbUriOrConfigName
+	statementTimeout := ""
+
+	if !isConnString(dbUriOrConfigName) {
+		var err error
+		rawURI, err = m.config.GetDbURI(dbUriOrConfigName)
+		if err != nil {
+			return nil, err
+		}
+
+		dbConfig, _ := m.config.GetDatabase(dbUriOrConfigName)
+		statementTimeout = dbConfig.StatementTimeout
+
+		password, ok, err := resolvePassword(ctx, dbConfig)
+		if err != nil {
+			return nil, fmt.Errorf("database alias '%s': %w", dbUriOrConfigName, err)
+		}
+		if ok {
+			connConfig, err := pgx.ParseConfig(injectPasswordInUri(rawURI, password))
+			if err != nil {
+				// The parse error may echo the connection string, so it is not wrapped
+				return nil, fmt.Errorf("database alias '%s': invalid connection string", dbUriOrConfigName)
+			}
+			return connectConfig(ctx, connConfig, statementTimeout)
+		}
 	}
 
 	safeUri := encodePasswordInUri(rawURI)
 
-	conn, err := pgx.Connect(ctx, safeUri)
+	connConfig, err := pgx.ParseConfig(safeUri)
+	if err != nil {
+		return nil, err
+	}
+
+	return connectConfig(ctx, connConfig, statementTimeout)
+}
+
+func connectConfig(ctx context.Context, connConfig *pgx.ConnConfig, statementTimeout string) (*pgx.Conn, error) {
+	// Applied to the whole session, so every query of the command is limited
+	if statementTimeout != "" {
+		connConfig.RuntimeParams["statement_timeout"] = statementTimeout
+	}
+
+	conn, err := pgx.ConnectConfig(ctx, connConfig)
 	if err != nil {
 		return nil, err
 	}
@@ -42,10 +88,30 @@ func (m *DbManager) Connect(ctx context.Context, dbUriOrConfigName string) (*pgx
 	return conn, nil
 }
 
+// isConnString reports whether the value is a connection URI or a key/value DSN rather than an alias.
+func isConnString(value string) bool {
+	return value == "" ||
+		strings.HasPrefix(value, "postgres://") ||
+		strings.HasPrefix(value, "postgresql://") ||
+		strings.Contains(value, "=")
+}
+
 func (m *DbManager) GetConfigDatabaseNames() []string {
 	return m.config.GetDatabaseNames()
 }
 
+func (m *DbManager) GetConfigDatabase(name string) (config.DatabaseConfig, bool) {
+	return m.config.GetDatabase(name)
+}
+
+func (m *DbManager) GetConfigSettingRules() []config.SettingRule {
+	return m.config.Settings.Rules
+}
+
+func (m *DbManager) GetConfigSchemaOwners() map[string]config.OwnerRule {
+	return m.config.SchemaOwner.Schemas
+}
+
 // encodePasswordInUri parses the connection string and URL-encodes the password.
 // Logic:
 // 1. Strip the scheme "postgres://".
@@ -90,8 +156,14 @@ func encodePasswordInUri(rawURI string) string {
 	password := credentialsPart[firstColon+1:]
 
 	// Encode the password (transforms M""4 into M%22%224)
-	encodedPassword := url.QueryEscape(password)
+	encodedPassword := encodePassword(password)
 
 	// Reassemble everything
 	return scheme + user + ":" + encodedPassword + "@" + hostPart
 }
+
+// encodePassword URL-encodes a raw password for the credentials part of a URI.
+// Userinfo escaping is used: query escaping turns a space into "+", which is not decoded back in userinfo.
+func encodePassword(password string) string {
+	return strings.TrimPrefix(url.UserPassword("", password).String(), ":")
+}
@@ -0,0 +1,94 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/pg-ok/pgok/internal/config"
+)
+
+// resolvePassword returns the password referenced by password_env, password_file or password_command.
+// The second value is false when the alias has no password reference.
+// Errors never contain the secret itself.
+func resolvePassword(ctx context.Context, dbConfig config.DatabaseConfig) (string, bool, error) {
+	references := 0
+	for _, ref := range []string{dbConfig.PasswordEnv, dbConfig.PasswordFile, dbConfig.PasswordCommand} {
+		if ref != "" {
+			references++
+		}
+	}
+
+	switch {
+	case references == 0:
+		return "", false, nil
+	case references > 1:
+		return "", false, errors.New("only one of password_env, password_file, password_command can be set")
+	}
+
+	if dbConfig.PasswordEnv != "" {
+		password, ok := os.LookupEnv(dbConfig.PasswordEnv)
+		if !ok || password == "" {
+			return "", false, fmt.Errorf("password_env: environment variable %s is not set", dbConfig.PasswordEnv)
+		}
+		return password, true, nil
+	}
+
+	if dbConfig.PasswordFile != "" {
+		content, err := os.ReadFile(dbConfig.PasswordFile)
+		if err != nil {
+			return "", false, fmt.Errorf("password_file: %w", err)
+		}
+		return strings.TrimRight(string(content), "\r\n"), true, nil
+	}
+
+	// The command output is the secret, so only the exit status is reported.
+	// Stderr is passed through, e.g. for interactive prompts of password managers.
+	command := exec.CommandContext(ctx, "sh", "-c", dbConfig.PasswordCommand)
+	command.Stdin = os.Stdin
+	command.Stderr = os.Stderr
+	output, err := command.Output()
+	if err != nil {
+		return "", false, fmt.Errorf("password_command failed: %w", err)
+	}
+
+	return strings.TrimRight(string(output), "\r\n"), true, nil
+}
+
+// injectPasswordInUri sets the password of a connection URI or key/value DSN.
+// For URIs the password is URL-encoded like in encodePasswordInUri.
+func injectPasswordInUri(rawURI string, password string) string {
+	for _, scheme := range []string{"postgres://", "postgresql://"} {
+		if !strings.HasPrefix(rawURI, scheme) {
+			continue
+		}
+
+		rest := rawURI[len(scheme):]
+
+		// Credentials can only appear before the path or query
+		authority := rest
+		if end := strings.IndexAny(rest, "/?"); end != -1 {
+			authority = rest[:end]
+		}
+
+		// Keep the user (if any), drop the password from the URI
+		user := ""
+		if lastAt := strings.LastIndex(authority, "@"); lastAt != -1 {
+			user = rest[:lastAt]
+			if firstColon := strings.Index(user, ":"); firstColon != -1 {
+				user = user[:firstColon]
+			}
+			rest = rest[lastAt+1:]
+		}
+
+		// Not passed through encodePasswordInUri: it splits at the last "@", which may be in the query
+		return scheme + user + ":" + encodePassword(password) + "@" + rest
+	}
+
+	// Key/value DSN: a later keyword overrides an earlier one
+	escaped := strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(password)
+	return strings.TrimSpace(rawURI + " password='" + escaped + "'")
+}
@@ -0,0 +1,76 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// StatStatements describes the pg_stat_statements extension installed in the current database.
+// Column names differ between extension versions: PG12 (1.7 and older) has total_time/mean_time,
+// PG13+ (1.8 and newer) has total_exec_time/mean_exec_time.
+type StatStatements struct {
+	View            string
+	TotalTimeColumn string
+	MeanTimeColumn  string
+}
+
+// DefaultStatStatements is the PG13+ layout, used to print queries without connecting (e.g. --explain).
+var DefaultStatStatements = &StatStatements{
+	View:            "pg_stat_statements",
+	TotalTimeColumn: "total_exec_time",
+	MeanTimeColumn:  "mean_exec_time",
+}
+
+// GetStatStatements checks that pg_stat_statements is installed and preloaded,
+// and detects its column naming. The returned error explains how to enable the extension.
+// shared_preload_libraries is readable by privileged roles only, so loading is checked by reading the view.
+func GetStatStatements(ctx context.Context, conn *pgx.Conn) (*StatStatements, error) {
+	var schema string
+	err := conn.QueryRow(ctx, `
+       SELECT n.nspname
+       FROM pg_extension AS e
+       JOIN pg_namespace AS n ON n.oid = e.extnamespace
+       WHERE e.extname = 'pg_stat_statements'
+    `).Scan(&schema)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, errors.New("pg_stat_statements extension is not installed in this database: run CREATE EXTENSION pg_stat_statements; (it must also be listed in 'shared_preload_libraries')")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	view := pgx.Identifier{schema, "pg_stat_statements"}.Sanitize()
+
+	// 55000 (object_not_in_prerequisite_state): "pg_stat_statements must be loaded via shared_preload_libraries"
+	_, err = conn.Exec(ctx, fmt.Sprintf("SELECT 1 FROM %s LIMIT 1", view))
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "55000" {
+		return nil, errors.New("pg_stat_statements extension is installed, but not loaded: add it to 'shared_preload_libraries' and restart the server")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pg_stat_statements: %w", err)
+	}
+
+	var hasExecTime bool
+	err = conn.QueryRow(ctx, `
+       SELECT EXISTS (
+          SELECT 1
+          FROM pg_attribute
+          WHERE attrelid = $1::REGCLASS
+            AND attname = 'total_exec_time'
+       )
+    `, view).Scan(&hasExecTime)
+	if err != nil {
+		return nil, fmt.Errorf("failed to inspect pg_stat_statements columns: %w", err)
+	}
+
+	if hasExecTime {
+		return &StatStatements{View: view, TotalTimeColumn: "total_exec_time", MeanTimeColumn: "mean_exec_time"}, nil
+	}
+
+	return &StatStatements{View: view, TotalTimeColumn: "total_time", MeanTimeColumn: "mean_time"}, nil
+}
@@ -0,0 +1,50 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// StatsAgeSql returns when cumulative statistics of the current database were last reset
+// and when the server was started.
+const StatsAgeSql = `SELECT stats_reset, pg_postmaster_start_time() FROM pg_stat_database WHERE datname = current_database();`
+
+// StatsAge describes how long usage statistics (scans, tuples, blocks) have been collected.
+// Findings based on statistics are only trustworthy when they cover a representative period.
+type StatsAge struct {
+	StatsReset  *time.Time `json:"stats_reset"` // Pointer to handle NULL (never reset)
+	ServerStart time.Time  `json:"server_start"`
+}
+
+func GetStatsAge(ctx context.Context, conn *pgx.Conn) (*StatsAge, error) {
+	var age StatsAge
+
+	err := conn.QueryRow(ctx, StatsAgeSql).Scan(&age.StatsReset, &age.ServerStart)
+	if err != nil {
+		return nil, err
+	}
+
+	return &age, nil
+}
+
+// Days returns the number of days statistics have been collected for.
+// If statistics were never reset, the period is unknown: they survive restarts (always on PG 15+,
+// after a clean shutdown before that), so only the time since the server start is returned
+// as a lower bound and exact is false.
+func (a *StatsAge) Days() (days float64, exact bool) {
+	if a.StatsReset != nil {
+		return time.Since(*a.StatsReset).Hours() / 24, true
+	}
+	return time.Since(a.ServerStart).Hours() / 24, false
+}
+
+func (a *StatsAge) String() string {
+	days, exact := a.Days()
+	if !exact {
+		return fmt.Sprintf("never reset, at least since %s (server start, %.0f+ days)", a.ServerStart.Format("2006-01-02 15:04"), days)
+	}
+	return fmt.Sprintf("since %s (%.0f days)", a.StatsReset.Format("2006-01-02 15:04"), days)
+}
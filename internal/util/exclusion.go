@@ -0,0 +1,29 @@
+package util
+
+import "github.com/spf13/pflag"
+
+// Exclusion skips objects by schema and table name (--exclude-schema, --exclude-table).
+// Tables match by name ("audit_log") or by qualified name ("public.audit_log").
+// The lists are passed to the SQL queries as TEXT[] parameters, so excluded objects are not scanned at all:
+//
+//	AND NOT (n.nspname = ANY($2::TEXT[]))
+//	AND NOT (c.relname = ANY($3::TEXT[]) OR n.nspname || '.' || c.relname = ANY($3::TEXT[]))
+type Exclusion struct {
+	Schemas []string
+	Tables  []string
+}
+
+// AddExclusionFlags registers --exclude-schema and --exclude-table.
+func AddExclusionFlags(flags *pflag.FlagSet, e *Exclusion) {
+	AddSchemaExclusionFlag(flags, e)
+	// Empty (not nil) defaults: nil is sent as NULL and "x = ANY(NULL)" would exclude everything
+	flags.StringSliceVar(&e.Tables, "exclude-table", []string{}, "Tables to skip, as name or schema.name (comma-separated or repeated)")
+}
+
+// AddSchemaExclusionFlag registers --exclude-schema only, for commands whose objects don't belong to a table.
+func AddSchemaExclusionFlag(flags *pflag.FlagSet, e *Exclusion) {
+	flags.StringSliceVar(&e.Schemas, "exclude-schema", []string{}, "Schemas to skip (comma-separated or repeated)")
+	if e.Tables == nil {
+		e.Tables = []string{}
+	}
+}
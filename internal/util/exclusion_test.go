@@ -0,0 +1,86 @@
+package util
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/spf13/pflag"
+)
+
+func TestAddExclusionFlags(t *testing.T) {
+	tests := []struct {
+		name        string
+		args        []string
+		wantSchemas []string
+		wantTables  []string
+	}{
+		{
+			name:        "defaults are empty, not nil",
+			args:        []string{},
+			wantSchemas: []string{},
+			wantTables:  []string{},
+		},
+		{
+			name:        "comma-separated",
+			args:        []string{"--exclude-schema=archive,tmp", "--exclude-table=audit_log,public.events"},
+			wantSchemas: []string{"archive", "tmp"},
+			wantTables:  []string{"audit_log", "public.events"},
+		},
+		{
+			name:        "repeated",
+			args:        []string{"--exclude-schema=archive", "--exclude-schema=tmp", "--exclude-table=audit_log"},
+			wantSchemas: []string{"archive", "tmp"},
+			wantTables:  []string{"audit_log"},
+		},
+		{
+			name:        "empty value",
+			args:        []string{"--exclude-schema="},
+			wantSchemas: []string{},
+			wantTables:  []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var exclude Exclusion
+			flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+			AddExclusionFlags(flags, &exclude)
+
+			if err := flags.Parse(tt.args); err != nil {
+				t.Fatalf("Parse(%v) failed: %v", tt.args, err)
+			}
+
+			// nil would be sent to PostgreSQL as NULL and exclude every row
+			if exclude.Schemas == nil || exclude.Tables == nil {
+				t.Fatalf("got nil lists: %+v", exclude)
+			}
+			if !reflect.DeepEqual(exclude.Schemas, tt.wantSchemas) {
+				t.Errorf("Schemas = %v, want %v", exclude.Schemas, tt.wantSchemas)
+			}
+			if !reflect.DeepEqual(exclude.Tables, tt.wantTables) {
+				t.Errorf("Tables = %v, want %v", exclude.Tables, tt.wantTables)
+			}
+		})
+	}
+}
+
+func TestAddSchemaExclusionFlag(t *testing.T) {
+	var exclude Exclusion
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	AddSchemaExclusionFlag(flags, &exclude)
+
+	if flags.Lookup("exclude-table") != nil {
+		t.Error("--exclude-table should not be registered")
+	}
+
+	if err := flags.Parse([]string{"--exclude-schema=archive"}); err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	if !reflect.DeepEqual(exclude.Schemas, []string{"archive"}) {
+		t.Errorf("Schemas = %v, want [archive]", exclude.Schemas)
+	}
+	if exclude.Tables == nil || len(exclude.Tables) != 0 {
+		t.Errorf("Tables = %#v, want empty non-nil list", exclude.Tables)
+	}
+}